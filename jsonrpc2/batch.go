@@ -0,0 +1,220 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Batch represents a JSON-RPC 2.0 batch.
+// Requests and notifications are queued with Call and Notify,
+// and sent to the peer as a single array by Send.
+// A Batch must not be used concurrently and can be sent only once.
+type Batch struct {
+	conn     *Conn
+	requests []*Request[any]
+	calls    []*BatchCall
+	sent     bool
+}
+
+// NewBatch creates a new batch on the connection.
+func (c *Conn) NewBatch() *Batch {
+	return &Batch{conn: c}
+}
+
+// BatchCall represents a request queued in a Batch.
+// The response is available after Send returns.
+type BatchCall struct {
+	id     ID
+	method Method
+	ch     chan json.RawMessage
+	resp   json.RawMessage
+	err    error
+}
+
+// ID returns the request ID of the call.
+func (c *BatchCall) ID() ID {
+	return c.id
+}
+
+// Decode unmarshals the response into result.
+// result receives the whole response object, in the same way as Conn.Call.
+// Decode returns an error if no response was received for the call.
+func (c *BatchCall) Decode(result any) error {
+	if c.err != nil {
+		return c.err
+	}
+	if c.resp == nil {
+		return errors.New("no response received")
+	}
+	return json.Unmarshal(c.resp, result)
+}
+
+// BatchResult is a typed handle to the response of a request queued in a Batch.
+type BatchResult[Result, ErrorData any] struct {
+	call *BatchCall
+}
+
+// Get returns the result and an error if the request fails.
+// When the result is unsuccessful, the error is `jsonrpc2.Error[ErrorData]` type.
+func (r *BatchResult[Result, ErrorData]) Get() (Result, error) {
+	var resp Response[Result, ErrorData]
+	if err := r.call.Decode(&resp); err != nil {
+		return resp.Result, err
+	}
+	return resp.tuple()
+}
+
+// CallInBatch queues a request in the batch.
+// The result is available from the returned BatchResult after the batch is sent.
+func CallInBatch[Result, ErrorData, Params any](b *Batch, method string, params Params) *BatchResult[Result, ErrorData] {
+	return &BatchResult[Result, ErrorData]{call: b.Call(method, params)}
+}
+
+// Call queues a request in the batch.
+func (b *Batch) Call(method string, params any) *BatchCall {
+	id := NewID(int(id.Add(1)))
+
+	call := &BatchCall{
+		id:     id,
+		method: Method(method),
+		ch:     make(chan json.RawMessage, 1),
+	}
+
+	b.requests = append(b.requests, &Request[any]{
+		ID:     id,
+		Method: Method(method),
+		Params: params,
+	})
+	b.calls = append(b.calls, call)
+
+	return call
+}
+
+// Notify queues a notification in the batch.
+func (b *Batch) Notify(method string, params any) {
+	b.requests = append(b.requests, &Request[any]{
+		Method: Method(method),
+		Params: params,
+	})
+}
+
+// Send sends the batch and waits for responses to all queued requests.
+// Send returns an error if the batch could not be sent or the responses did not arrive.
+// Errors returned by the peer for individual requests are reported by each call.
+// The batch is bounded by the shortest call timeout of its requests,
+// and Send returns a TimeoutError when it is exceeded.
+// When Send stops waiting for the responses, the peer is notified of each unanswered request by the CancelNotifier if set.
+func (b *Batch) Send(ctx context.Context) error {
+	c := b.conn
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.closed:
+		return errors.New("connection closed")
+	default:
+	}
+
+	if b.sent {
+		return errors.New("batch already sent")
+	}
+	if len(b.requests) == 0 {
+		return errors.New("empty batch")
+	}
+	b.sent = true
+
+	if timeout, method := b.callTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, &TimeoutError{Method: string(method), Timeout: timeout})
+		defer cancel()
+	}
+
+	messages := make([]json.RawMessage, 0, len(b.requests))
+	for _, req := range b.requests {
+		msg, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, msg)
+	}
+
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	for _, call := range b.calls {
+		c.pending[call.id] = call.ch
+	}
+	c.mutex.Unlock()
+
+	if err := c.transport.Send(data); err != nil {
+		b.abort(err)
+		return err
+	}
+
+	for _, call := range b.calls {
+		select {
+		case resp := <-call.ch:
+			call.resp = resp
+		case <-ctx.Done():
+			err := ctx.Err()
+			if cause := context.Cause(ctx); errors.As(cause, new(*TimeoutError)) {
+				err = cause
+			}
+			for _, call := range b.abort(err) {
+				c.notifyCancel(ctx, call.id)
+			}
+			return err
+		case <-c.closed:
+			err := errors.New("connection closed")
+			b.abort(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// callTimeout returns the shortest call timeout of the queued requests and its method.
+func (b *Batch) callTimeout() (time.Duration, Method) {
+	var (
+		timeout time.Duration
+		method  Method
+	)
+	for _, call := range b.calls {
+		if t := b.conn.callTimeoutFor(call.method); t > 0 && (timeout == 0 || t < timeout) {
+			timeout, method = t, call.method
+		}
+	}
+	return timeout, method
+}
+
+// abort removes the unanswered calls from the pending responses and marks them as failed.
+// The responses that have already arrived are kept.
+// abort returns the calls marked as failed.
+func (b *Batch) abort(err error) []*BatchCall {
+	c := b.conn
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var aborted []*BatchCall
+	for _, call := range b.calls {
+		if call.resp != nil {
+			continue
+		}
+		delete(c.pending, call.id)
+		select {
+		case resp := <-call.ch:
+			call.resp = resp
+		default:
+			call.err = err
+			aborted = append(aborted, call)
+		}
+	}
+	return aborted
+}
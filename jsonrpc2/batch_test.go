@@ -0,0 +1,184 @@
+package jsonrpc2
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Warashi/go-modelcontextprotocol/transport"
+)
+
+func TestBatch_Send(t *testing.T) {
+	a, b := transport.NewPipe()
+
+	var notified atomic.Bool
+
+	conn1 := NewConnection(a,
+		WithHandler("testMethod", &testHandler{}),
+		WithHandlerFunc("echo", func(ctx context.Context, req []string) (string, error) {
+			return req[0], nil
+		}),
+		WithHandlerFunc("notify", func(ctx context.Context, req any) (any, error) {
+			notified.Store(true)
+			return nil, nil
+		}),
+	)
+	go conn1.Serve(t.Context())
+	conn2 := NewConnection(b)
+	conn2.Open()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+
+	batch := conn2.NewBatch()
+	r1 := CallInBatch[map[string]any, any](batch, "testMethod", map[string]any{"param1": "value1"})
+	r2 := CallInBatch[string, any](batch, "echo", []string{"hello"})
+	r3 := CallInBatch[any, any](batch, "nonExistentMethod", struct{}{})
+	batch.Notify("notify", struct{}{})
+
+	if err := batch.Send(ctx); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	result1, err := r1.Get()
+	if err != nil {
+		t.Fatalf("testMethod failed: %v", err)
+	}
+	if !jsonEqual(result1, map[string]any{"response": "success"}) {
+		t.Errorf("testMethod result = %v; want %v", result1, map[string]any{"response": "success"})
+	}
+
+	result2, err := r2.Get()
+	if err != nil {
+		t.Fatalf("echo failed: %v", err)
+	}
+	if result2 != "hello" {
+		t.Errorf("echo result = %v; want %v", result2, "hello")
+	}
+
+	_, err = r3.Get()
+	var rpcErr Error[any]
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected Error[any], got %T: %v", err, err)
+	}
+	if rpcErr.Code != CodeMethodNotFound {
+		t.Errorf("error code = %d; want %d", rpcErr.Code, CodeMethodNotFound)
+	}
+
+	if !notified.Load() {
+		t.Error("notification handler not called")
+	}
+}
+
+func TestBatch_SendErrors(t *testing.T) {
+	a, b := transport.NewPipe()
+
+	conn1 := NewConnection(a)
+	conn2 := NewConnection(b)
+	go conn1.Serve(t.Context())
+	conn2.Open()
+
+	// Test empty batch
+	if err := conn2.NewBatch().Send(t.Context()); err == nil {
+		t.Error("Send of empty batch should fail")
+	}
+
+	// Test sending twice
+	batch := conn2.NewBatch()
+	batch.Notify("notify", struct{}{})
+	if err := batch.Send(t.Context()); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if err := batch.Send(t.Context()); err == nil {
+		t.Error("Send of already sent batch should fail")
+	}
+
+	// Test context cancellation
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	batch = conn2.NewBatch()
+	call := batch.Call("testMethod", struct{}{})
+	if err := batch.Send(ctx); err == nil {
+		t.Error("Send with cancelled context should fail")
+	}
+	if err := call.Decode(new(any)); err == nil {
+		t.Error("Decode of unsent call should fail")
+	}
+
+	// Test connection closed
+	conn2.Close()
+	batch = conn2.NewBatch()
+	batch.Call("testMethod", struct{}{})
+	if err := batch.Send(t.Context()); err == nil {
+		t.Error("Send with closed connection should fail")
+	}
+
+	// Clean up
+	conn1.Close()
+}
+
+func TestBatch_SendTimeout(t *testing.T) {
+	a, b := transport.NewPipe()
+
+	release := make(chan struct{})
+	conn1 := NewConnection(a,
+		WithHandlerFunc("slow", func(ctx context.Context, req any) (any, error) {
+			<-release
+			return nil, nil
+		}),
+		WithHandlerFunc("fast", func(ctx context.Context, req any) (any, error) {
+			return "fast", nil
+		}),
+	)
+	go conn1.Serve(t.Context())
+
+	cancelled := make(chan ID, 2)
+	conn2 := NewConnection(b,
+		WithCallTimeout(10*time.Millisecond),
+		WithMethodCallTimeout("fast", time.Second),
+		WithCancelNotifier(func(ctx context.Context, c *Conn, id ID, reason string) error {
+			cancelled <- id
+			return nil
+		}),
+	)
+	conn2.Open()
+
+	batch := conn2.NewBatch()
+	fast := batch.Call("fast", struct{}{})
+	slow := batch.Call("slow", struct{}{})
+	err := batch.Send(t.Context())
+	close(release)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error wrapping context.DeadlineExceeded, got %v", err)
+	}
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected *TimeoutError, got %T: %v", err, err)
+	}
+	if timeoutErr.Method != "slow" || timeoutErr.Timeout != 10*time.Millisecond {
+		t.Errorf("TimeoutError = %+v; want method slow and timeout 10ms", timeoutErr)
+	}
+
+	// The responses of a batch are sent together, so neither call is answered
+	want := map[string]bool{fast.ID().String(): true, slow.ID().String(): true}
+	for range 2 {
+		select {
+		case id := <-cancelled:
+			if !want[id.String()] {
+				t.Errorf("cancel notifier called with unexpected ID %v", id)
+			}
+			delete(want, id.String())
+		case <-time.After(1 * time.Second):
+			t.Fatal("cancel notifier not called")
+		}
+	}
+
+	for _, call := range []*BatchCall{fast, slow} {
+		if err := call.Decode(new(any)); !errors.As(err, &timeoutErr) {
+			t.Errorf("Decode of call %v error = %v; want *TimeoutError", call.ID(), err)
+		}
+	}
+}
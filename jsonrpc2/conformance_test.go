@@ -0,0 +1,290 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Warashi/go-modelcontextprotocol/transport"
+)
+
+// newSpecConn creates a connection with the handlers used in the examples of the JSON-RPC 2.0 specification.
+func newSpecConn(t transport.Session) *Conn {
+	return NewConnection(t,
+		WithHandlerFunc("subtract", func(ctx context.Context, req json.RawMessage) (int, error) {
+			var positional []int
+			if err := json.Unmarshal(req, &positional); err == nil && len(positional) == 2 {
+				return positional[0] - positional[1], nil
+			}
+			var named struct {
+				Minuend    int `json:"minuend"`
+				Subtrahend int `json:"subtrahend"`
+			}
+			if err := json.Unmarshal(req, &named); err != nil {
+				return 0, NewError[any](CodeInvalidParams, "Invalid params", nil)
+			}
+			return named.Minuend - named.Subtrahend, nil
+		}),
+		WithHandlerFunc("sum", func(ctx context.Context, req []int) (int, error) {
+			sum := 0
+			for _, v := range req {
+				sum += v
+			}
+			return sum, nil
+		}),
+		WithHandlerFunc("get_data", func(ctx context.Context, req any) ([]any, error) {
+			return []any{"hello", 5}, nil
+		}),
+		WithHandlerFunc("notify_hello", func(ctx context.Context, req []int) (any, error) {
+			return nil, nil
+		}),
+		WithHandlerFunc("notify_sum", func(ctx context.Context, req []int) (any, error) {
+			return nil, nil
+		}),
+		WithHandlerFunc("update", func(ctx context.Context, req []int) (any, error) {
+			return nil, nil
+		}),
+		WithHandlerFunc("fail", func(ctx context.Context, req any) (any, error) {
+			return nil, NewError(-32001, "custom error", "detail")
+		}),
+		WithHandlerFunc("fail_wrapped", func(ctx context.Context, req any) (any, error) {
+			return nil, errors.Join(errors.New("wrapped"), NewError[any](CodeInvalidParams, "Invalid params", nil))
+		}),
+		WithHandlerFunc("unmarshalable", func(ctx context.Context, req any) (unmarshalable, error) {
+			return unmarshalable{}, nil
+		}),
+		WithHandlerFunc("unmarshalable_data", func(ctx context.Context, req any) (any, error) {
+			return nil, NewError[any](-32001, "custom error", unmarshalable{})
+		}),
+	)
+}
+
+// unmarshalable is a value whose MarshalJSON fails.
+type unmarshalable struct{}
+
+func (unmarshalable) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("cannot marshal")
+}
+
+// TestConformance runs the examples from the JSON-RPC 2.0 specification.
+// See https://www.jsonrpc.org/specification#examples
+func TestConformance(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  string
+		response string // empty if no response is expected
+	}{
+		{
+			name:     "rpc call with positional parameters",
+			request:  `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`,
+			response: `{"jsonrpc": "2.0", "result": 19, "id": 1}`,
+		},
+		{
+			name:     "rpc call with positional parameters reversed",
+			request:  `{"jsonrpc": "2.0", "method": "subtract", "params": [23, 42], "id": 2}`,
+			response: `{"jsonrpc": "2.0", "result": -19, "id": 2}`,
+		},
+		{
+			name:     "rpc call with named parameters",
+			request:  `{"jsonrpc": "2.0", "method": "subtract", "params": {"subtrahend": 23, "minuend": 42}, "id": 3}`,
+			response: `{"jsonrpc": "2.0", "result": 19, "id": 3}`,
+		},
+		{
+			name:     "rpc call with named parameters reordered",
+			request:  `{"jsonrpc": "2.0", "method": "subtract", "params": {"minuend": 42, "subtrahend": 23}, "id": 4}`,
+			response: `{"jsonrpc": "2.0", "result": 19, "id": 4}`,
+		},
+		{
+			name:    "a notification",
+			request: `{"jsonrpc": "2.0", "method": "update", "params": [1,2,3,4,5]}`,
+		},
+		{
+			name:    "a notification without handler",
+			request: `{"jsonrpc": "2.0", "method": "foobar"}`,
+		},
+		{
+			name:     "rpc call of non-existent method",
+			request:  `{"jsonrpc": "2.0", "method": "foobar", "id": "1"}`,
+			response: `{"jsonrpc": "2.0", "error": {"code": -32601, "message": "method not found"}, "id": "1"}`,
+		},
+		{
+			name:     "rpc call with invalid JSON",
+			request:  `{"jsonrpc": "2.0", "method": "foobar, "params": "bar", "baz]`,
+			response: `{"jsonrpc": "2.0", "error": {"code": -32700, "message": "Parse error"}, "id": null}`,
+		},
+		{
+			name:     "rpc call with invalid Request object",
+			request:  `{"jsonrpc": "2.0", "method": 1, "params": "bar"}`,
+			response: `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}`,
+		},
+		{
+			name: "rpc call Batch, invalid JSON",
+			request: `[
+				{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": "1"},
+				{"jsonrpc": "2.0", "method"
+			]`,
+			response: `{"jsonrpc": "2.0", "error": {"code": -32700, "message": "Parse error"}, "id": null}`,
+		},
+		{
+			name:     "rpc call with an empty Array",
+			request:  `[]`,
+			response: `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}`,
+		},
+		{
+			name:     "rpc call with an invalid Batch (but not empty)",
+			request:  `[1]`,
+			response: `[{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}]`,
+		},
+		{
+			name:    "rpc call with invalid Batch",
+			request: `[1,2,3]`,
+			response: `[
+				{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null},
+				{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null},
+				{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}
+			]`,
+		},
+		{
+			name: "rpc call Batch",
+			request: `[
+				{"jsonrpc": "2.0", "method": "sum", "params": [1,2,4], "id": "1"},
+				{"jsonrpc": "2.0", "method": "notify_hello", "params": [7]},
+				{"jsonrpc": "2.0", "method": "subtract", "params": [42,23], "id": "2"},
+				{"foo": "boo"},
+				{"jsonrpc": "2.0", "method": "foo.get", "params": {"name": "myself"}, "id": "5"},
+				{"jsonrpc": "2.0", "method": "get_data", "id": "9"}
+			]`,
+			response: `[
+				{"jsonrpc": "2.0", "result": 7, "id": "1"},
+				{"jsonrpc": "2.0", "result": 19, "id": "2"},
+				{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null},
+				{"jsonrpc": "2.0", "error": {"code": -32601, "message": "method not found"}, "id": "5"},
+				{"jsonrpc": "2.0", "result": ["hello", 5], "id": "9"}
+			]`,
+		},
+		{
+			name: "rpc call Batch (all notifications)",
+			request: `[
+				{"jsonrpc": "2.0", "method": "notify_sum", "params": [1,2,4]},
+				{"jsonrpc": "2.0", "method": "notify_hello", "params": [7]}
+			]`,
+		},
+		{
+			name:     "rpc call with invalid params",
+			request:  `{"jsonrpc": "2.0", "method": "sum", "params": {"a": 1}, "id": 1}`,
+			response: `{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params"}, "id": 1}`,
+		},
+		{
+			name:     "handler error keeps its code",
+			request:  `{"jsonrpc": "2.0", "method": "fail", "id": 1}`,
+			response: `{"jsonrpc": "2.0", "error": {"code": -32001, "message": "custom error", "data": "detail"}, "id": 1}`,
+		},
+		{
+			name:     "handler error keeps its code in batch",
+			request:  `[{"jsonrpc": "2.0", "method": "fail", "id": 1}]`,
+			response: `[{"jsonrpc": "2.0", "error": {"code": -32001, "message": "custom error", "data": "detail"}, "id": 1}]`,
+		},
+		{
+			name:     "wrapped handler error keeps its code",
+			request:  `[{"jsonrpc": "2.0", "method": "fail_wrapped", "id": 1}]`,
+			response: `[{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params"}, "id": 1}]`,
+		},
+		{
+			name:     "result which cannot be marshaled",
+			request:  `{"jsonrpc": "2.0", "method": "unmarshalable", "id": 1}`,
+			response: `{"jsonrpc": "2.0", "error": {"code": -32603, "message": "Internal error"}, "id": 1}`,
+		},
+		{
+			name:     "error data which cannot be marshaled",
+			request:  `{"jsonrpc": "2.0", "method": "unmarshalable_data", "id": 1}`,
+			response: `{"jsonrpc": "2.0", "error": {"code": -32603, "message": "Internal error"}, "id": 1}`,
+		},
+		{
+			name: "result which cannot be marshaled in batch",
+			request: `[
+				{"jsonrpc": "2.0", "method": "unmarshalable", "id": 1},
+				{"jsonrpc": "2.0", "method": "sum", "params": [1,2], "id": 2}
+			]`,
+			response: `[
+				{"jsonrpc": "2.0", "error": {"code": -32603, "message": "Internal error"}, "id": 1},
+				{"jsonrpc": "2.0", "result": 3, "id": 2}
+			]`,
+		},
+		{
+			name:     "invalid Request object keeps its ID",
+			request:  `{"jsonrpc": "2.0", "method": "sum", "params": "bar", "id": 1}`,
+			response: `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": 1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dt := &dummyTransport{}
+			conn := newSpecConn(dt)
+
+			if err := conn.handleMessage(context.Background(), json.RawMessage(tt.request)); err != nil {
+				t.Fatalf("handleMessage returned error: %v", err)
+			}
+
+			got := dt.lastSentMessage()
+			if tt.response == "" {
+				if got != nil {
+					t.Errorf("expected no response, got %s", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("expected response %s, got none", tt.response)
+			}
+
+			var want, actual any
+			if err := json.Unmarshal([]byte(tt.response), &want); err != nil {
+				t.Fatalf("failed to unmarshal expected response: %v", err)
+			}
+			if err := json.Unmarshal(got, &actual); err != nil {
+				t.Fatalf("failed to unmarshal actual response: %v", err)
+			}
+			if !reflect.DeepEqual(want, actual) {
+				t.Errorf("response mismatch:\nwant %s\ngot  %s", tt.response, got)
+			}
+		})
+	}
+}
+
+// TestConformance_ServeAfterMarshalError checks that a response which cannot be marshaled doesn't stop serving.
+func TestConformance_ServeAfterMarshalError(t *testing.T) {
+	a, b := transport.NewPipe()
+
+	server := newSpecConn(a)
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(t.Context()) }()
+
+	client := NewConnection(b)
+	client.Open()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+
+	_, err := Call[any, any](ctx, client, "unmarshalable", struct{}{})
+	var rpcErr Error[any]
+	if !errors.As(err, &rpcErr) || rpcErr.Code != CodeInternalError {
+		t.Fatalf("expected Internal error, got %v", err)
+	}
+
+	result, err := Call[int, any](ctx, client, "sum", []int{1, 2})
+	if err != nil {
+		t.Fatalf("Call after marshal error failed: %v", err)
+	}
+	if result != 3 {
+		t.Errorf("sum = %d; want 3", result)
+	}
+
+	select {
+	case err := <-served:
+		t.Errorf("Serve returned early: %v", err)
+	default:
+	}
+}
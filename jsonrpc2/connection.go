@@ -7,6 +7,7 @@ import (
 	"errors"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/Warashi/go-modelcontextprotocol/transport"
 )
@@ -29,7 +30,7 @@ func RegisterHandler[Params, Result any](c *Conn, method string, h Handler[Param
 	c.handlers[Method(method)] = func(ctx context.Context, req json.RawMessage) (any, error) {
 		var r Request[Params]
 		if err := json.Unmarshal(req, &r); err != nil {
-			return nil, err
+			return nil, NewError[any](CodeInvalidParams, "Invalid params", nil)
 		}
 		return h.HandleRequest(ctx, r.Params)
 	}
@@ -67,6 +68,17 @@ type Conn struct {
 	handlers  map[Method]func(ctx context.Context, req json.RawMessage) (any, error)
 	closed    chan struct{}
 	logger    *slog.Logger
+
+	callTimeout     time.Duration
+	callTimeouts    map[Method]time.Duration
+	handlerTimeout  time.Duration
+	handlerTimeouts map[Method]time.Duration
+	cancelNotifier  CancelNotifier
+
+	// inflight tracks the messages being handled and the handlers still running.
+	inflight sync.WaitGroup
+	// shuttingDown is set by Shutdown, and guarded by mutex.
+	shuttingDown bool
 }
 
 // NewConnection creates a new JSON-RPC 2.0 connection.
@@ -77,6 +89,9 @@ func NewConnection(transport transport.Session, opts ...ConnectionInitialization
 		handlers:  make(map[Method]func(ctx context.Context, req json.RawMessage) (any, error)),
 		closed:    make(chan struct{}),
 		logger:    slog.New(slog.DiscardHandler),
+
+		callTimeouts:    make(map[Method]time.Duration),
+		handlerTimeouts: make(map[Method]time.Duration),
 	}
 
 	for _, opt := range opts {
@@ -137,6 +152,8 @@ func (c *Conn) Close() error {
 }
 
 // Call sends a request to the server and waits for a response.
+// If a call timeout is configured for the method, Call returns a TimeoutError when it is exceeded.
+// When Call stops waiting for the response, the peer is notified by the CancelNotifier if set.
 func (c *Conn) Call(ctx context.Context, id ID, method string, params any, result any) error {
 	select {
 	case <-ctx.Done():
@@ -146,6 +163,12 @@ func (c *Conn) Call(ctx context.Context, id ID, method string, params any, resul
 	default:
 	}
 
+	if timeout := c.callTimeoutFor(Method(method)); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, &TimeoutError{Method: method, Timeout: timeout})
+		defer cancel()
+	}
+
 	req := &Request[any]{
 		ID:     id,
 		Method: Method(method),
@@ -177,6 +200,10 @@ func (c *Conn) Call(ctx context.Context, id ID, method string, params any, resul
 		c.mutex.Lock()
 		delete(c.pending, id)
 		c.mutex.Unlock()
+		c.notifyCancel(ctx, id)
+		if err := context.Cause(ctx); errors.As(err, new(*TimeoutError)) {
+			return err
+		}
 		return ctx.Err()
 	}
 }
@@ -205,7 +232,7 @@ func (c *Conn) Notify(ctx context.Context, method string, params any) error {
 }
 
 // serve starts serving requests.
-// serve will return an error if the connection is closed.
+// serve will return an error if the connection is closed, or if the transport fails to send a response.
 func (c *Conn) serve(ctx context.Context) error {
 	select {
 	case <-ctx.Done():
@@ -235,139 +262,184 @@ func (c *Conn) serve(ctx context.Context) error {
 }
 
 // handleMessage reads a message from the connection and handles it.
+// Single messages and batches are dispatched in the same way,
+// and any response is sent back to the peer.
 func (c *Conn) handleMessage(ctx context.Context, msg json.RawMessage) error {
+	if c.acquire() {
+		defer c.inflight.Done()
+	}
+
 	trimmedMsg := bytes.TrimSpace(msg)
 	if len(trimmedMsg) > 0 && trimmedMsg[0] == '[' {
 		var batch []json.RawMessage
 		if err := json.Unmarshal(msg, &batch); err != nil {
-			errResp := c.generateErrorResponse(ID{value: nil}, CodeParseError, "Parse error")
-			b, _ := json.Marshal(errResp)
-			c.mutex.Lock()
-			defer c.mutex.Unlock()
-			return c.transport.Send(b)
+			return c.send(ctx, c.generateErrorResponse(ID{value: nil}, CodeParseError, "Parse error"))
 		}
 		return c.handleBatchMessage(ctx, batch)
-	} else {
-		var obj map[string]any
-		if err := json.Unmarshal(msg, &obj); err != nil {
-			errResp := c.generateErrorResponse(ID{value: nil}, CodeParseError, "Parse error")
-			b, _ := json.Marshal(errResp)
-			c.mutex.Lock()
-			defer c.mutex.Unlock()
-			return c.transport.Send(b)
+	}
+
+	var obj any
+	if err := json.Unmarshal(msg, &obj); err != nil {
+		return c.send(ctx, c.generateErrorResponse(ID{value: nil}, CodeParseError, "Parse error"))
+	}
+
+	resp := c.dispatch(ctx, msg)
+	if resp == nil {
+		return nil
+	}
+	return c.send(ctx, resp)
+}
+
+// handleBatchMessage processes a batch of JSON-RPC 2.0 messages, collects responses for requests and sends a single batch response.
+func (c *Conn) handleBatchMessage(ctx context.Context, batch []json.RawMessage) error {
+	if len(batch) == 0 {
+		return c.send(ctx, c.generateErrorResponse(ID{value: nil}, CodeInvalidRequest, "Invalid Request"))
+	}
+
+	var responses []*Response[any, any]
+	for _, msg := range batch {
+		if resp := c.dispatch(ctx, msg); resp != nil {
+			responses = append(responses, resp)
 		}
-		_ = c.handleRawMessage(ctx, msg)
+	}
+
+	if len(responses) == 0 {
+		// No response for notifications and responses
 		return nil
 	}
+	return c.send(ctx, responses)
 }
 
-// handleRawMessage handles a JSON-RPC 2.0 single message.
-func (c *Conn) handleRawMessage(ctx context.Context, msg json.RawMessage) error {
-	switch t, err := getMessageType(msg); t {
+// dispatch handles a JSON-RPC 2.0 single message and returns the response to be sent.
+// dispatch returns nil when no response is needed, i.e. for notifications and responses.
+// Invalid messages are answered with an Invalid Request error.
+func (c *Conn) dispatch(ctx context.Context, msg json.RawMessage) *Response[any, any] {
+	t, err := getMessageType(msg)
+	if err != nil {
+		c.logger.DebugContext(ctx, "invalid message", slog.String("message", string(msg)), slog.String("error", err.Error()))
+		return c.generateErrorResponse(getMessageID(msg), CodeInvalidRequest, "Invalid Request")
+	}
+
+	switch t {
 	case messageRequest:
 		return c.handleRequest(ctx, msg)
-	case messageResponse:
-		return c.handleResponse(ctx, msg)
 	case messageNotification:
-		return c.handleNotification(ctx, msg)
-	default:
-		if err != nil {
-			return err
+		if err := c.handleNotification(ctx, msg); err != nil {
+			c.logger.DebugContext(ctx, "handleNotification", slog.String("error", err.Error()))
 		}
-		return errors.New("invalid message type")
+		return nil
+	case messageResponse:
+		if err := c.handleResponse(ctx, msg); err != nil {
+			c.logger.DebugContext(ctx, "handleResponse", slog.String("error", err.Error()))
+		}
+		return nil
+	default:
+		panic("unreachable")
 	}
 }
 
-// handleRequest handles a JSON-RPC 2.0 request message.
-func (c *Conn) handleRequest(ctx context.Context, msg json.RawMessage) error {
+// handleRequest handles a JSON-RPC 2.0 request message and returns the response.
+func (c *Conn) handleRequest(ctx context.Context, msg json.RawMessage) *Response[any, any] {
 	c.logger.DebugContext(ctx, "handleRequest", slog.String("message", string(msg)))
 
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	default:
-	}
-
 	var req Request[json.RawMessage]
 	if err := json.Unmarshal(msg, &req); err != nil {
-		return err
+		return c.generateErrorResponse(getMessageID(msg), CodeInvalidRequest, "Invalid Request")
 	}
 
-	handler, ok := c.handlers[req.Method]
-	if !ok {
-		return c.sendError(ctx, req.ID, NewError[any](-32601, "method not found", nil))
-	}
-
-	resp, err := handler(ctx, msg)
+	result, err := c.invoke(ctx, req.Method, msg)
 	if err != nil {
-		return c.sendError(ctx, req.ID, err)
-	}
-
-	return c.sendResponse(ctx, req.ID, resp)
-}
-
-// sendResponse sends a JSON-RPC 2.0 response message.
-func (c *Conn) sendResponse(ctx context.Context, id ID, resp any) error {
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	default:
-	}
-
-	if id.IsNull() {
-		return errors.New("invalid response ID")
+		rpcErr, hidden := convertError(err)
+		if hidden {
+			c.logger.ErrorContext(ctx, "handleRequest", slog.String("method", string(req.Method)), slog.String("error", err.Error()))
+		}
+		if rpcErr.Data != nil {
+			data, err := json.Marshal(rpcErr.Data)
+			if err != nil {
+				c.logger.ErrorContext(ctx, "handleRequest", slog.String("method", string(req.Method)), slog.String("error", err.Error()))
+				return c.generateErrorResponse(req.ID, CodeInternalError, "Internal error")
+			}
+			rpcErr.Data = json.RawMessage(data)
+		}
+		return &Response[any, any]{
+			ID:    req.ID,
+			Error: rpcErr,
+		}
 	}
 
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	b, err := json.Marshal(&Response[any, any]{
-		ID:     id,
-		Result: resp,
-	})
+	// The result is marshaled here, so that a result which cannot be marshaled is answered with an Internal error
+	// instead of failing to send the response
+	b, err := json.Marshal(result)
 	if err != nil {
-		return err
+		c.logger.ErrorContext(ctx, "handleRequest", slog.String("method", string(req.Method)), slog.String("error", err.Error()))
+		return c.generateErrorResponse(req.ID, CodeInternalError, "Internal error")
+	}
+	return &Response[any, any]{
+		ID:     req.ID,
+		Result: json.RawMessage(b),
 	}
+}
 
-	c.logger.DebugContext(ctx, "sendResponse", slog.String("body", string(b)))
+// handleNotification handles a JSON-RPC 2.0 notification message.
+func (c *Conn) handleNotification(ctx context.Context, msg json.RawMessage) error {
+	c.logger.DebugContext(ctx, "handleNotification", slog.String("message", string(msg)))
 
-	if err := c.transport.Send(b); err != nil {
+	var req Request[json.RawMessage]
+	if err := json.Unmarshal(msg, &req); err != nil {
 		return err
 	}
 
-	return nil
+	_, err := c.invoke(ctx, req.Method, msg)
+	return err
 }
 
-// sendError sends a JSON-RPC 2.0 error response message.
-func (c *Conn) sendError(ctx context.Context, id ID, err error) error {
+// invoke calls the handler registered for the method.
+// invoke returns a Method not found error if no handler is registered,
+// and a TimeoutError if the handler exceeds its timeout.
+func (c *Conn) invoke(ctx context.Context, method Method, msg json.RawMessage) (any, error) {
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
+		return nil, ctx.Err()
 	default:
 	}
 
-	if id.IsNull() {
-		return errors.New("invalid error ID")
+	if c.isShuttingDown() {
+		return nil, NewError[any](CodeServerShuttingDown, "connection is shutting down", nil)
 	}
 
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	handler, ok := c.handlers[method]
+	if !ok {
+		return nil, NewError[any](CodeMethodNotFound, "method not found", nil)
+	}
 
-	b, err := json.Marshal(&Response[any, any]{
-		ID:    id,
-		Error: convertError(err),
-	})
-	if err != nil {
-		return err
+	timeout := c.handlerTimeoutFor(method)
+	if timeout <= 0 {
+		return handler(ctx, msg)
 	}
 
-	c.logger.DebugContext(ctx, "sendError", slog.String("body", string(b)))
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, &TimeoutError{Method: string(method), Timeout: timeout})
+	defer cancel()
 
-	if err := c.transport.Send(b); err != nil {
-		return err
+	type handlerResult struct {
+		result any
+		err    error
 	}
+	ch := make(chan handlerResult, 1)
+	c.inflight.Add(1)
+	go func() {
+		defer c.inflight.Done()
+		result, err := handler(ctx, msg)
+		ch <- handlerResult{result: result, err: err}
+	}()
 
-	return nil
+	select {
+	case r := <-ch:
+		return r.result, r.err
+	case <-ctx.Done():
+		// The handler keeps running until it observes the cancellation,
+		// but the peer gets the timeout error without waiting for it.
+		return nil, context.Cause(ctx)
+	}
 }
 
 // handleResponse handles a JSON-RPC 2.0 response message.
@@ -407,24 +479,25 @@ func (c *Conn) handleResponse(ctx context.Context, msg json.RawMessage) error {
 	}
 }
 
-// handleNotification handles a JSON-RPC 2.0 notification message.
-func (c *Conn) handleNotification(ctx context.Context, msg json.RawMessage) error {
-	var req Request[json.RawMessage]
-	if err := json.Unmarshal(msg, &req); err != nil {
-		return err
-	}
-
-	handler, ok := c.handlers[req.Method]
-	if !ok {
-		return errors.New("method not found")
+// send sends a JSON-RPC 2.0 message or batch to the peer.
+func (c *Conn) send(ctx context.Context, v any) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
 	}
 
-	_, err := handler(ctx, msg)
+	b, err := json.Marshal(v)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.logger.DebugContext(ctx, "send", slog.String("body", string(b)))
+
+	return c.transport.Send(b)
 }
 
 // generateErrorResponse creates a JSON-RPC 2.0 error response.
@@ -434,80 +507,3 @@ func (c *Conn) generateErrorResponse(id ID, code int, message string) *Response[
 		Error: NewError[any](code, message, nil),
 	}
 }
-
-// handleBatchMessage processes a batch of JSON-RPC 2.0 messages, collects responses for requests and sends a single batch response.
-func (c *Conn) handleBatchMessage(ctx context.Context, batch []json.RawMessage) error {
-	if len(batch) == 0 {
-		errResp := c.generateErrorResponse(ID{value: nil}, CodeInvalidRequest, "Invalid Request")
-		b, _ := json.Marshal(errResp)
-		c.mutex.Lock()
-		defer c.mutex.Unlock()
-		return c.transport.Send(b)
-	}
-
-	var responses []json.RawMessage
-
-	for _, msg := range batch {
-		mType, err := getMessageType(msg)
-		if err != nil {
-			errResp := c.generateErrorResponse(ID{value: nil}, CodeInvalidRequest, "Invalid Request")
-			b, _ := json.Marshal(errResp)
-			responses = append(responses, b)
-			continue
-		}
-
-		switch mType {
-		case messageRequest:
-			var req Request[json.RawMessage]
-			if err := json.Unmarshal(msg, &req); err != nil {
-				errResp := c.generateErrorResponse(ID{value: nil}, CodeParseError, "Parse error")
-				b, _ := json.Marshal(errResp)
-				responses = append(responses, b)
-				continue
-			}
-
-			handler, ok := c.handlers[req.Method]
-			if !ok {
-				errResp := c.generateErrorResponse(req.ID, CodeMethodNotFound, "method not found")
-				b, _ := json.Marshal(errResp)
-				responses = append(responses, b)
-				continue
-			}
-
-			result, err := handler(ctx, msg)
-			if err != nil {
-				errResp := c.generateErrorResponse(req.ID, CodeInternalError, err.Error())
-				b, _ := json.Marshal(errResp)
-				responses = append(responses, b)
-				continue
-			}
-
-			resp := Response[any, any]{
-				ID:     req.ID,
-				Result: result,
-			}
-			b, _ := json.Marshal(resp)
-			responses = append(responses, b)
-		case messageNotification:
-			var req Request[json.RawMessage]
-			if err := json.Unmarshal(msg, &req); err != nil {
-				continue
-			}
-			if handler, ok := c.handlers[req.Method]; ok {
-				handler(ctx, msg)
-			}
-			// No response for notifications
-		default:
-			// Ignore other message types in batch
-		}
-	}
-
-	if len(responses) > 0 {
-		batchResp, _ := json.Marshal(responses)
-		c.mutex.Lock()
-		defer c.mutex.Unlock()
-		return c.transport.Send(batchResp)
-	}
-
-	return nil
-}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"log/slog"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -64,6 +66,37 @@ func TestConn_MethodNotFound(t *testing.T) {
 	}
 }
 
+func TestConn_InternalError(t *testing.T) {
+	a, b := transport.NewPipe()
+
+	var logs bytes.Buffer
+	conn1 := NewConnection(a,
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		WithHandlerFunc("fail", func(ctx context.Context, req any) (any, error) {
+			return nil, errors.New("secret: database password is wrong")
+		}),
+	)
+	go conn1.Serve(t.Context())
+	conn2 := NewConnection(b)
+	conn2.Open()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+
+	_, err := Call[any, any](ctx, conn2, "fail", struct{}{})
+	var rpcErr Error[any]
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected Error[any], got %T: %v", err, err)
+	}
+	if rpcErr.Code != CodeInternalError || rpcErr.Message != "Internal error" {
+		t.Errorf("error = %+v; want generic Internal error", rpcErr)
+	}
+
+	if !strings.Contains(logs.String(), "secret: database password is wrong") {
+		t.Errorf("expected the original error to be logged, got %q", logs.String())
+	}
+}
+
 func jsonEqual(a, b any) bool {
 	aj, _ := json.Marshal(a)
 	bj, _ := json.Marshal(b)
@@ -216,38 +249,25 @@ func TestConn_CallErrors(t *testing.T) {
 	conn1.Close()
 }
 
-func TestConn_SendResponseAndError(t *testing.T) {
+func TestConn_Send(t *testing.T) {
 	a, b := transport.NewPipe()
 
 	conn1 := NewConnection(a)
 	conn2 := NewConnection(b)
 	go conn1.Serve(context.Background())
 
-	// Test sendResponse with null ID
-	ctx := context.Background()
-	err := conn1.sendResponse(ctx, ID{value: nil}, nil)
-	if err == nil {
-		t.Error("sendResponse with null ID should fail")
-	}
-
-	// Test sendError with null ID
-	err = conn1.sendError(ctx, ID{value: nil}, errors.New("test error"))
-	if err == nil {
-		t.Error("sendError with null ID should fail")
-	}
-
-	// Test sendResponse with cancelled context
+	// Test send with cancelled context
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
-	err = conn1.sendResponse(ctx, NewID("test"), nil)
+	err := conn1.send(ctx, &Response[any, any]{ID: NewID("test")})
 	if err == nil {
-		t.Error("sendResponse with cancelled context should fail")
+		t.Error("send with cancelled context should fail")
 	}
 
-	// Test sendError with cancelled context
-	err = conn1.sendError(ctx, NewID("test"), errors.New("test error"))
+	// Test send with unmarshalable message
+	err = conn1.send(context.Background(), &Response[any, any]{ID: NewID("test"), Result: make(chan int)})
 	if err == nil {
-		t.Error("sendError with cancelled context should fail")
+		t.Error("send with unmarshalable message should fail")
 	}
 
 	// Clean up
@@ -173,17 +173,28 @@ type Response[Result, ErrorData any] struct {
 }
 
 // MarshalJSON implements the json.Marshaler interface.
+// The id member is always present, and is null if the request ID could not be determined.
+// Either the result or the error member is present, depending on whether the request succeeded.
 func (r *Response[Result, ErrorData]) MarshalJSON() ([]byte, error) {
+	if r.Error.Code != 0 {
+		return json.Marshal(struct {
+			JSONRPC string           `json:"jsonrpc"`
+			ID      ID               `json:"id"`
+			Error   Error[ErrorData] `json:"error"`
+		}{
+			JSONRPC: "2.0",
+			ID:      r.ID,
+			Error:   r.Error,
+		})
+	}
 	return json.Marshal(struct {
-		JSONRPC string           `json:"jsonrpc"`
-		ID      ID               `json:"id,omitzero"`
-		Result  Result           `json:"result,omitempty,omitzero"`
-		Error   Error[ErrorData] `json:"error,omitzero"`
+		JSONRPC string `json:"jsonrpc"`
+		ID      ID     `json:"id"`
+		Result  Result `json:"result"`
 	}{
 		JSONRPC: "2.0",
 		ID:      r.ID,
 		Result:  r.Result,
-		Error:   r.Error,
 	})
 }
 
@@ -254,29 +265,44 @@ func (e Error[Data]) data() any {
 	return e.Data
 }
 
+// ErrorCoder is implemented by errors that define the JSON-RPC 2.0 error sent to the peer.
+// Handlers can return an ErrorCoder, or an error wrapping it, to control the code, message and data of the error response.
+type ErrorCoder interface {
+	error
+	// JSONRPCError returns the JSON-RPC 2.0 error to be sent.
+	JSONRPCError() Error[any]
+}
+
 // convertError converts an error to a JSON-RPC 2.0 error.
-func convertError(err error) Error[any] {
+// Errors that are neither an ErrorCoder nor an Error are converted to a generic Internal error,
+// so that their messages are not exposed to the peer, and convertError reports that the error is hidden.
+func convertError(err error) (Error[any], bool) {
 	if err == nil {
 		panic("nil error")
 	}
 
-	if e, ok := err.(interface {
+	var coder ErrorCoder
+	if errors.As(err, &coder) {
+		return coder.JSONRPCError(), false
+	}
+
+	var e interface {
 		code() int
 		message() string
 		data() any
-	}); ok {
+	}
+	if errors.As(err, &e) {
 		return Error[any]{
 			Code:    e.code(),
 			Message: e.message(),
 			Data:    e.data(),
-		}
+		}, false
 	}
 
 	return Error[any]{
-		Code:    -32000,
-		Message: err.Error(),
-		Data:    err,
-	}
+		Code:    CodeInternalError,
+		Message: "Internal error",
+	}, true
 }
 
 // messageType represents a JSON-RPC 2.0 message type.
@@ -303,6 +329,28 @@ func getMessageType(msg json.RawMessage) (messageType, error) {
 		return 0, errors.New("invalid JSON-RPC version")
 	}
 
+	if id, ok := v["id"]; ok {
+		switch id.(type) {
+		case string, float64, nil:
+		default:
+			return 0, errors.New("invalid ID type")
+		}
+	}
+
+	if method, ok := v["method"]; ok {
+		if _, ok := method.(string); !ok {
+			return 0, errors.New("invalid method type")
+		}
+	}
+
+	if params, ok := v["params"]; ok {
+		switch params.(type) {
+		case map[string]any, []any, nil:
+		default:
+			return 0, errors.New("invalid params type")
+		}
+	}
+
 	if _, ok := v["error"]; ok {
 		// if error is present, it's a response
 		// error response may not have an id
@@ -330,3 +378,15 @@ func getMessageType(msg json.RawMessage) (messageType, error) {
 	// otherwise, it's invalid
 	return 0, errors.New("invalid message type")
 }
+
+// getMessageID returns the ID of a JSON-RPC 2.0 message.
+// getMessageID returns a null ID if the ID cannot be determined.
+func getMessageID(msg json.RawMessage) ID {
+	var v struct {
+		ID ID `json:"id"`
+	}
+	if err := json.Unmarshal(msg, &v); err != nil {
+		return ID{value: nil}
+	}
+	return v.ID
+}
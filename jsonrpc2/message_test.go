@@ -211,7 +211,7 @@ func TestResponse_MarshalJSON(t *testing.T) {
 		},
 		{
 			resp:     &Response[any, any]{ID: ID{value: nil}, Result: nil, Error: Error[any]{Code: -32000, Message: "error"}},
-			expected: `{"jsonrpc":"2.0","error":{"code":-32000,"message":"error"}}`,
+			expected: `{"jsonrpc":"2.0","id":null,"error":{"code":-32000,"message":"error"}}`,
 		},
 	}
 
@@ -324,25 +324,44 @@ func TestConvertError(t *testing.T) {
 	tests := []struct {
 		input    error
 		expected Error[any]
+		hidden   bool
 	}{
 		{
 			input:    errors.New("standard error"),
-			expected: Error[any]{Code: -32000, Message: "standard error", Data: errors.New("standard error")},
+			expected: Error[any]{Code: CodeInternalError, Message: "Internal error"},
+			hidden:   true,
 		},
 		{
 			input:    NewError(-32001, "custom error", "data"),
 			expected: Error[any]{Code: -32001, Message: "custom error", Data: "data"},
 		},
+		{
+			input:    fmt.Errorf("wrapped: %w", coderError{}),
+			expected: Error[any]{Code: -32002, Message: "coder error", Data: "coder data"},
+		},
 	}
 
 	for _, test := range tests {
-		result := convertError(test.input)
+		result, hidden := convertError(test.input)
 		if result.Code != test.expected.Code || result.Message != test.expected.Message || fmt.Sprintf("%v", result.Data) != fmt.Sprintf("%v", test.expected.Data) {
 			t.Errorf("convertError(%v) = %v; want %v", test.input, result, test.expected)
 		}
+		if hidden != test.hidden {
+			t.Errorf("convertError(%v) hidden = %v; want %v", test.input, hidden, test.hidden)
+		}
 	}
 }
 
+type coderError struct{}
+
+func (e coderError) Error() string {
+	return "coder error"
+}
+
+func (e coderError) JSONRPCError() Error[any] {
+	return NewError[any](-32002, "coder error", "coder data")
+}
+
 type customError struct {
 	errCode    int
 	errMessage string
@@ -372,7 +391,7 @@ func TestConvertError_CustomError(t *testing.T) {
 		errData:    "error data",
 	}
 
-	converted := convertError(err)
+	converted, _ := convertError(err)
 	if converted.Code != -32001 {
 		t.Errorf("Expected code -32001, got %d", converted.Code)
 	}
@@ -420,6 +439,21 @@ func TestGetMessageType(t *testing.T) {
 			expected: 0,
 			err:      errors.New("invalid JSON-RPC version"),
 		},
+		{
+			input:    `{"jsonrpc":"2.0","method":1,"params":"bar"}`,
+			expected: 0,
+			err:      errors.New("invalid method type"),
+		},
+		{
+			input:    `{"jsonrpc":"2.0","method":"testMethod","params":"bar"}`,
+			expected: 0,
+			err:      errors.New("invalid params type"),
+		},
+		{
+			input:    `{"jsonrpc":"2.0","method":"testMethod","id":{}}`,
+			expected: 0,
+			err:      errors.New("invalid ID type"),
+		},
 	}
 
 	for _, test := range tests {
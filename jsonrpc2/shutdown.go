@@ -0,0 +1,55 @@
+package jsonrpc2
+
+import (
+	"context"
+	"errors"
+)
+
+// CodeServerShuttingDown is the error code for requests received while the connection is shutting down.
+const CodeServerShuttingDown = -32000
+
+// Shutdown gracefully shuts down the connection.
+// Shutdown stops accepting new requests, which are answered with a CodeServerShuttingDown error,
+// and waits for the in-flight handlers to finish and their responses to be sent.
+// Then Shutdown closes the connection.
+// If ctx is done before the handlers finish, Shutdown closes the connection anyway and returns the context error.
+// Responses to requests sent by Call are still handled while Shutdown is waiting.
+func (c *Conn) Shutdown(ctx context.Context) error {
+	c.mutex.Lock()
+	c.shuttingDown = true
+	c.mutex.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		c.inflight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return c.Close()
+	case <-ctx.Done():
+		return errors.Join(ctx.Err(), c.Close())
+	}
+}
+
+// acquire marks a message as being handled.
+// acquire returns false if the connection is shutting down, and the message is not tracked.
+func (c *Conn) acquire() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.shuttingDown {
+		return false
+	}
+	c.inflight.Add(1)
+	return true
+}
+
+// isShuttingDown reports whether Shutdown has been called.
+func (c *Conn) isShuttingDown() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.shuttingDown
+}
@@ -0,0 +1,137 @@
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Warashi/go-modelcontextprotocol/transport"
+)
+
+func TestConn_Shutdown(t *testing.T) {
+	a, b := transport.NewPipe()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	conn1 := NewConnection(a,
+		WithHandlerFunc("slow", func(ctx context.Context, req any) (string, error) {
+			close(started)
+			<-release
+			return "done", nil
+		}),
+	)
+	served := make(chan error, 1)
+	go func() { served <- conn1.Serve(t.Context()) }()
+	conn2 := NewConnection(b)
+	conn2.Open()
+
+	type callResult struct {
+		result string
+		err    error
+	}
+	called := make(chan callResult, 1)
+	go func() {
+		result, err := Call[string, any](t.Context(), conn2, "slow", struct{}{})
+		called <- callResult{result: result, err: err}
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- conn1.Shutdown(t.Context()) }()
+
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned before the in-flight request finished: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+
+	select {
+	case r := <-called:
+		if r.err != nil {
+			t.Fatalf("Call failed: %v", r.err)
+		}
+		if r.result != "done" {
+			t.Errorf("Call result = %v; want %v", r.result, "done")
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("in-flight call did not complete")
+	}
+
+	select {
+	case err := <-shutdown:
+		if err != nil {
+			t.Errorf("Shutdown failed: %v", err)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("Shutdown did not return")
+	}
+
+	select {
+	case <-served:
+	case <-time.After(1 * time.Second):
+		t.Fatal("Serve did not return after Shutdown")
+	}
+}
+
+func TestConn_ShutdownContextDone(t *testing.T) {
+	a, b := transport.NewPipe()
+
+	started := make(chan struct{})
+	conn1 := NewConnection(a,
+		WithHandlerFunc("stuck", func(ctx context.Context, req any) (any, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}),
+	)
+	go conn1.Serve(t.Context())
+	conn2 := NewConnection(b)
+	conn2.Open()
+	defer conn2.Close()
+
+	go Call[any, any](t.Context(), conn2, "stuck", struct{}{})
+	<-started
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := conn1.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	select {
+	case <-conn1.closed:
+	default:
+		t.Error("connection is not closed after Shutdown")
+	}
+}
+
+func TestConn_ShutdownRejectsNewRequests(t *testing.T) {
+	dt := &dummyTransport{}
+	conn := NewConnection(dt,
+		WithHandlerFunc("echo", func(ctx context.Context, req any) (any, error) {
+			return req, nil
+		}),
+	)
+
+	if err := conn.Shutdown(t.Context()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	msg := json.RawMessage(`{"jsonrpc":"2.0","method":"echo","params":[],"id":1}`)
+	if err := conn.handleMessage(t.Context(), msg); err != nil {
+		t.Fatalf("handleMessage failed: %v", err)
+	}
+
+	var resp Response[any, any]
+	if err := json.Unmarshal(dt.lastSentMessage(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if resp.Error.Code != CodeServerShuttingDown {
+		t.Errorf("error code = %d; want %d", resp.Error.Code, CodeServerShuttingDown)
+	}
+}
@@ -0,0 +1,118 @@
+package jsonrpc2
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// TimeoutError is returned when a call or a handler exceeds its timeout.
+// TimeoutError wraps context.DeadlineExceeded.
+type TimeoutError struct {
+	// Method is the method of the request that timed out.
+	Method string
+	// Timeout is the timeout that was exceeded.
+	Timeout time.Duration
+}
+
+// Error implements the error interface.
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s: timed out after %s", e.Method, e.Timeout)
+}
+
+// Unwrap returns context.DeadlineExceeded.
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// code returns the error code.
+func (e *TimeoutError) code() int {
+	return CodeInternalError
+}
+
+// message returns the error message.
+func (e *TimeoutError) message() string {
+	return e.Error()
+}
+
+// data returns the error data as an any.
+func (e *TimeoutError) data() any {
+	return nil
+}
+
+// CancelNotifier notifies the peer that a request sent by Call is no longer awaited.
+// reason describes why the request was abandoned, e.g. the timeout was exceeded.
+type CancelNotifier func(ctx context.Context, c *Conn, id ID, reason string) error
+
+// WithCallTimeout sets the default timeout for requests sent by Call.
+// A zero or negative timeout means no timeout.
+func WithCallTimeout(timeout time.Duration) ConnectionInitializationOption {
+	return func(c *Conn) {
+		c.callTimeout = timeout
+	}
+}
+
+// WithMethodCallTimeout sets the timeout for requests of the method sent by Call.
+// It overrides the default timeout set by WithCallTimeout.
+// A zero or negative timeout means no timeout.
+func WithMethodCallTimeout(method string, timeout time.Duration) ConnectionInitializationOption {
+	return func(c *Conn) {
+		c.callTimeouts[Method(method)] = timeout
+	}
+}
+
+// WithHandlerTimeout sets the default timeout for request handlers.
+// When a handler exceeds the timeout, its context is cancelled and the peer receives the error.
+// A zero or negative timeout means no timeout.
+func WithHandlerTimeout(timeout time.Duration) ConnectionInitializationOption {
+	return func(c *Conn) {
+		c.handlerTimeout = timeout
+	}
+}
+
+// WithMethodHandlerTimeout sets the timeout for the handler of the method.
+// It overrides the default timeout set by WithHandlerTimeout.
+// A zero or negative timeout means no timeout.
+func WithMethodHandlerTimeout(method string, timeout time.Duration) ConnectionInitializationOption {
+	return func(c *Conn) {
+		c.handlerTimeouts[Method(method)] = timeout
+	}
+}
+
+// WithCancelNotifier sets a notifier that is called when Call stops waiting for a response,
+// because its context is done or its timeout is exceeded.
+func WithCancelNotifier(notifier CancelNotifier) ConnectionInitializationOption {
+	return func(c *Conn) {
+		c.cancelNotifier = notifier
+	}
+}
+
+// callTimeoutFor returns the timeout for requests of the method.
+func (c *Conn) callTimeoutFor(method Method) time.Duration {
+	if timeout, ok := c.callTimeouts[method]; ok {
+		return timeout
+	}
+	return c.callTimeout
+}
+
+// handlerTimeoutFor returns the timeout for the handler of the method.
+func (c *Conn) handlerTimeoutFor(method Method) time.Duration {
+	if timeout, ok := c.handlerTimeouts[method]; ok {
+		return timeout
+	}
+	return c.handlerTimeout
+}
+
+// notifyCancel notifies the peer that the request is no longer awaited.
+// ctx is the context of the abandoned call, and is expected to be done.
+func (c *Conn) notifyCancel(ctx context.Context, id ID) {
+	if c.cancelNotifier == nil {
+		return
+	}
+
+	reason := context.Cause(ctx).Error()
+	if err := c.cancelNotifier(context.WithoutCancel(ctx), c, id, reason); err != nil {
+		c.logger.DebugContext(ctx, "notifyCancel", slog.String("id", id.String()), slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,114 @@
+package jsonrpc2
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Warashi/go-modelcontextprotocol/transport"
+)
+
+func TestConn_HandlerTimeout(t *testing.T) {
+	a, b := transport.NewPipe()
+
+	blocking := func(ctx context.Context, req any) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	conn1 := NewConnection(a,
+		WithHandlerTimeout(10*time.Millisecond),
+		WithMethodHandlerTimeout("unlimited", 0),
+		WithHandlerFunc("slow", blocking),
+		WithHandlerFunc("unlimited", func(ctx context.Context, req any) (any, error) {
+			time.Sleep(30 * time.Millisecond)
+			return "done", nil
+		}),
+	)
+	go conn1.Serve(t.Context())
+	conn2 := NewConnection(b)
+	conn2.Open()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 1*time.Second)
+	defer cancel()
+
+	_, err := Call[any, any](ctx, conn2, "slow", struct{}{})
+	var rpcErr Error[any]
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected Error[any], got %T: %v", err, err)
+	}
+	if rpcErr.Code != CodeInternalError {
+		t.Errorf("error code = %d; want %d", rpcErr.Code, CodeInternalError)
+	}
+	if !strings.Contains(rpcErr.Message, "timed out") {
+		t.Errorf("error message = %q; want it to contain %q", rpcErr.Message, "timed out")
+	}
+
+	result, err := Call[string, any](ctx, conn2, "unlimited", struct{}{})
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if result != "done" {
+		t.Errorf("Call result = %v; want %v", result, "done")
+	}
+}
+
+func TestConn_CallTimeout(t *testing.T) {
+	a, b := transport.NewPipe()
+
+	release := make(chan struct{})
+	conn1 := NewConnection(a,
+		WithHandlerFunc("slow", func(ctx context.Context, req any) (any, error) {
+			<-release
+			return nil, nil
+		}),
+		WithHandlerFunc("fast", func(ctx context.Context, req any) (any, error) {
+			return "fast", nil
+		}),
+	)
+	go conn1.Serve(t.Context())
+
+	cancelled := make(chan ID, 1)
+	conn2 := NewConnection(b,
+		WithCallTimeout(10*time.Millisecond),
+		WithMethodCallTimeout("fast", 0),
+		WithCancelNotifier(func(ctx context.Context, c *Conn, id ID, reason string) error {
+			cancelled <- id
+			return nil
+		}),
+	)
+	conn2.Open()
+
+	_, err := Call[any, any](t.Context(), conn2, "slow", struct{}{})
+	close(release)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error wrapping context.DeadlineExceeded, got %v", err)
+	}
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected *TimeoutError, got %T: %v", err, err)
+	}
+	if timeoutErr.Method != "slow" || timeoutErr.Timeout != 10*time.Millisecond {
+		t.Errorf("TimeoutError = %+v; want method slow and timeout 10ms", timeoutErr)
+	}
+
+	select {
+	case id := <-cancelled:
+		if id.IsNull() {
+			t.Error("cancel notifier called with null ID")
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("cancel notifier not called")
+	}
+
+	result, err := Call[string, any](t.Context(), conn2, "fast", struct{}{})
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if result != "fast" {
+		t.Errorf("Call result = %v; want %v", result, "fast")
+	}
+}
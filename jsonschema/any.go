@@ -0,0 +1,37 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Any is a JSON schema that accepts any value, that is, the empty schema.
+type Any struct {
+	Description string `json:"description,omitempty"`
+}
+
+// Validate validates the value against the JSON schema.
+func (s Any) Validate(v json.RawMessage) error {
+	var m any
+	if err := json.Unmarshal(v, &m); err != nil {
+		return fmt.Errorf("unmarshal: %w", err)
+	}
+
+	return validateValue(s, m)
+}
+
+// validate validates the value against the JSON schema.
+func (s Any) validate(c *validationContext, location string, v any) []Violation {
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s Any) MarshalJSON() ([]byte, error) {
+	type anySchema Any
+
+	return json.Marshal(struct {
+		anySchema
+	}{
+		anySchema: anySchema(s),
+	})
+}
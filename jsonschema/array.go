@@ -20,31 +20,31 @@ func (s Array) Validate(v json.RawMessage) error {
 		return fmt.Errorf("unmarshal: %w", err)
 	}
 
-	return s.validate(m)
+	return validateValue(s, m)
 }
 
 // validate validates the array against the JSON schema.
-func (s Array) validate(v any) error {
+func (s Array) validate(c *validationContext, location string, v any) []Violation {
 	arr, ok := v.([]any)
 	if !ok {
-		return fmt.Errorf("value is not an array")
+		return []Violation{newViolation(location, "type", "value is not an array")}
 	}
 
+	var violations []Violation
+
 	if s.MinItems > 0 && len(arr) < s.MinItems {
-		return fmt.Errorf("array has too few items")
+		violations = append(violations, newViolation(location, "minItems", "array has too few items"))
 	}
 
 	if s.MaxItems > 0 && len(arr) > s.MaxItems {
-		return fmt.Errorf("array has too many items")
+		violations = append(violations, newViolation(location, "maxItems", "array has too many items"))
 	}
 
 	for i, v := range arr {
-		if err := s.Items.validate(v); err != nil {
-			return fmt.Errorf("item %d: %w", i, err)
-		}
+		violations = append(violations, s.Items.validate(c, itemLocation(location, i), v)...)
 	}
 
-	return nil
+	return violations
 }
 
 // MarshalJSON implements the json.Marshaler interface.
@@ -17,14 +17,14 @@ func (s Boolean) Validate(v json.RawMessage) error {
 		return fmt.Errorf("unmarshal: %w", err)
 	}
 
-	return s.validate(m)
+	return validateValue(s, m)
 }
 
 // validate validates the boolean against the JSON schema.
-func (s Boolean) validate(v any) error {
+func (s Boolean) validate(c *validationContext, location string, v any) []Violation {
 	_, ok := v.(bool)
 	if !ok {
-		return fmt.Errorf("value is not a boolean")
+		return []Violation{newViolation(location, "type", "value is not a boolean")}
 	}
 
 	return nil
@@ -0,0 +1,229 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// OneOf is a JSON schema oneOf.
+// The value must be valid against exactly one of the schemas.
+type OneOf struct {
+	Description string   `json:"description,omitempty"`
+	Schemas     []Schema `json:"oneOf"`
+}
+
+// Validate validates the value against the JSON schema.
+func (s OneOf) Validate(v json.RawMessage) error {
+	var m any
+	if err := json.Unmarshal(v, &m); err != nil {
+		return fmt.Errorf("unmarshal: %w", err)
+	}
+
+	return validateValue(s, m)
+}
+
+// validate validates the value against the JSON schema.
+func (s OneOf) validate(c *validationContext, location string, v any) []Violation {
+	var matched []int
+	var failed branches
+	for i, schema := range s.Schemas {
+		if violations := schema.validate(c, location, v); len(violations) > 0 {
+			failed = append(failed, branch{index: i, location: location, violations: violations})
+			continue
+		}
+		matched = append(matched, i)
+	}
+
+	switch len(matched) {
+	case 0:
+		return []Violation{newViolation(location, "oneOf", "value does not match any schema of oneOf: %s", failed)}
+	case 1:
+		return nil
+	default:
+		return []Violation{newViolation(location, "oneOf", "value matches more than one schema of oneOf: schemas %s", joinIndexes(matched))}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s OneOf) MarshalJSON() ([]byte, error) {
+	if len(s.Schemas) == 0 {
+		return nil, fmt.Errorf("oneOf must have at least one schema")
+	}
+
+	type oneOfSchema OneOf
+
+	return json.Marshal(struct {
+		oneOfSchema
+	}{
+		oneOfSchema: oneOfSchema(s),
+	})
+}
+
+// AnyOf is a JSON schema anyOf.
+// The value must be valid against at least one of the schemas.
+type AnyOf struct {
+	Description string   `json:"description,omitempty"`
+	Schemas     []Schema `json:"anyOf"`
+}
+
+// Validate validates the value against the JSON schema.
+func (s AnyOf) Validate(v json.RawMessage) error {
+	var m any
+	if err := json.Unmarshal(v, &m); err != nil {
+		return fmt.Errorf("unmarshal: %w", err)
+	}
+
+	return validateValue(s, m)
+}
+
+// validate validates the value against the JSON schema.
+func (s AnyOf) validate(c *validationContext, location string, v any) []Violation {
+	var failed branches
+	for i, schema := range s.Schemas {
+		if violations := schema.validate(c, location, v); len(violations) > 0 {
+			failed = append(failed, branch{index: i, location: location, violations: violations})
+			continue
+		}
+		return nil
+	}
+
+	return []Violation{newViolation(location, "anyOf", "value does not match any schema of anyOf: %s", failed)}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s AnyOf) MarshalJSON() ([]byte, error) {
+	if len(s.Schemas) == 0 {
+		return nil, fmt.Errorf("anyOf must have at least one schema")
+	}
+
+	type anyOfSchema AnyOf
+
+	return json.Marshal(struct {
+		anyOfSchema
+	}{
+		anyOfSchema: anyOfSchema(s),
+	})
+}
+
+// AllOf is a JSON schema allOf.
+// The value must be valid against all of the schemas.
+type AllOf struct {
+	Description string   `json:"description,omitempty"`
+	Schemas     []Schema `json:"allOf"`
+	// Defs are the definitions referred by Ref, e.g. "#/$defs/name", when the AllOf is the root schema.
+	// Parse uses it for a root with definitions which isn't an Object.
+	Defs map[string]Schema `json:"$defs,omitempty"`
+}
+
+// Validate validates the value against the JSON schema.
+func (s AllOf) Validate(v json.RawMessage) error {
+	var m any
+	if err := json.Unmarshal(v, &m); err != nil {
+		return fmt.Errorf("unmarshal: %w", err)
+	}
+
+	return validateValue(s, m)
+}
+
+// validate validates the value against the JSON schema.
+// As every schema must hold, the violations of the schemas are reported as they are, with their own locations.
+func (s AllOf) validate(c *validationContext, location string, v any) []Violation {
+	var violations []Violation
+	for _, schema := range s.Schemas {
+		violations = append(violations, schema.validate(c, location, v)...)
+	}
+	return violations
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s AllOf) MarshalJSON() ([]byte, error) {
+	if len(s.Schemas) == 0 {
+		return nil, fmt.Errorf("allOf must have at least one schema")
+	}
+
+	type allOfSchema AllOf
+
+	return json.Marshal(struct {
+		allOfSchema
+	}{
+		allOfSchema: allOfSchema(s),
+	})
+}
+
+// Not is a JSON schema not.
+// The value must not be valid against the schema.
+type Not struct {
+	Description string `json:"description,omitempty"`
+	Schema      Schema `json:"not"`
+}
+
+// Validate validates the value against the JSON schema.
+func (s Not) Validate(v json.RawMessage) error {
+	var m any
+	if err := json.Unmarshal(v, &m); err != nil {
+		return fmt.Errorf("unmarshal: %w", err)
+	}
+
+	return validateValue(s, m)
+}
+
+// validate validates the value against the JSON schema.
+func (s Not) validate(c *validationContext, location string, v any) []Violation {
+	if violations := s.Schema.validate(c, location, v); len(violations) > 0 {
+		return nil
+	}
+
+	return []Violation{newViolation(location, "not", "value must not match the schema of not")}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s Not) MarshalJSON() ([]byte, error) {
+	if s.Schema == nil {
+		return nil, fmt.Errorf("not must have a schema")
+	}
+
+	type notSchema Not
+
+	return json.Marshal(struct {
+		notSchema
+	}{
+		notSchema: notSchema(s),
+	})
+}
+
+// branch is a schema of a combinator that the value doesn't match, with the index of the schema.
+type branch struct {
+	index int
+	// location is the location of the value validated by the combinator
+	location   string
+	violations []Violation
+}
+
+// String returns the violations of the branch, located relative to the value validated by the combinator.
+func (b branch) String() string {
+	return fmt.Sprintf("schema %d: %s", b.index, relativeMessages(b.location, b.violations))
+}
+
+// branches is the list of the schemas of a combinator that the value doesn't match.
+type branches []branch
+
+func (b branches) String() string {
+	strs := make([]string, len(b))
+	for i, branch := range b {
+		strs[i] = branch.String()
+	}
+	return strings.Join(strs, "; ")
+}
+
+// joinIndexes formats the indexes of the schemas, e.g. "0, 1 and 3".
+func joinIndexes(indexes []int) string {
+	strs := make([]string, len(indexes))
+	for i, index := range indexes {
+		strs[i] = fmt.Sprint(index)
+	}
+	if len(strs) == 1 {
+		return strs[0]
+	}
+	return strings.Join(strs[:len(strs)-1], ", ") + " and " + strs[len(strs)-1]
+}
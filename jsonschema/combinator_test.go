@@ -0,0 +1,193 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCombinators_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  Schema
+		input   json.RawMessage
+		wantErr string
+	}{
+		{
+			name:   "oneOf matches one schema",
+			schema: OneOf{Schemas: []Schema{String{}, Integer{}}},
+			input:  json.RawMessage(`"value"`),
+		},
+		{
+			name:    "oneOf matches no schema",
+			schema:  OneOf{Schemas: []Schema{String{}, Integer{}}},
+			input:   json.RawMessage(`true`),
+			wantErr: "value does not match any schema of oneOf: schema 0: value is not a string; schema 1: value is not a number",
+		},
+		{
+			name:    "oneOf matches more than one schema",
+			schema:  OneOf{Schemas: []Schema{Number{}, Integer{}, String{}, Const{Value: 1.0}}},
+			input:   json.RawMessage(`1`),
+			wantErr: "value matches more than one schema of oneOf: schemas 0, 1 and 3",
+		},
+		{
+			name:   "anyOf matches one schema",
+			schema: AnyOf{Schemas: []Schema{String{}, Null{}}},
+			input:  json.RawMessage(`null`),
+		},
+		{
+			name:   "anyOf matches more than one schema",
+			schema: AnyOf{Schemas: []Schema{Number{}, Integer{}}},
+			input:  json.RawMessage(`1`),
+		},
+		{
+			name:    "anyOf matches no schema",
+			schema:  AnyOf{Schemas: []Schema{String{MinLength: 3}, Null{}}},
+			input:   json.RawMessage(`"ab"`),
+			wantErr: "value does not match any schema of anyOf: schema 0: string is too short; schema 1: value is not null",
+		},
+		{
+			name:   "allOf matches all schemas",
+			schema: AllOf{Schemas: []Schema{String{MinLength: 1}, String{MaxLength: 3}}},
+			input:  json.RawMessage(`"abc"`),
+		},
+		{
+			name:    "allOf fails on some schemas",
+			schema:  AllOf{Schemas: []Schema{String{MinLength: 1}, String{MaxLength: 3}, String{MaxLength: 2}}},
+			input:   json.RawMessage(`"abcd"`),
+			wantErr: "string is too long; string is too long",
+		},
+		{
+			name:   "not with a non-matching value",
+			schema: Not{Schema: Null{}},
+			input:  json.RawMessage(`"value"`),
+		},
+		{
+			name:    "not with a matching value",
+			schema:  Not{Schema: Null{}},
+			input:   json.RawMessage(`null`),
+			wantErr: "value must not match the schema of not",
+		},
+		{
+			name: "nested combinators",
+			schema: AnyOf{Schemas: []Schema{
+				AllOf{Schemas: []Schema{Integer{}, Not{Schema: Const{Value: 0.0}}}},
+				Null{},
+			}},
+			input:   json.RawMessage(`0`),
+			wantErr: "value does not match any schema of anyOf: schema 0: value must not match the schema of not; schema 1: value is not null",
+		},
+		{
+			name: "combinator in object property",
+			schema: Object{
+				Properties: map[string]Schema{
+					"id": OneOf{Schemas: []Schema{String{}, Integer{}}},
+				},
+			},
+			input:   json.RawMessage(`{"id": 1.5}`),
+			wantErr: "/id: value does not match any schema of oneOf: schema 0: value is not a string; schema 1: value is not an integer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schema.Validate(tt.input)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCombinators_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  Schema
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "oneOf",
+			schema: OneOf{Description: "an id", Schemas: []Schema{String{}, Integer{}}},
+			want:   `{"description":"an id","oneOf":[{"type":"string"},{"type":"integer"}]}`,
+		},
+		{
+			name:   "anyOf",
+			schema: AnyOf{Schemas: []Schema{String{}, Null{}}},
+			want:   `{"anyOf":[{"type":"string"},{"type":"null"}]}`,
+		},
+		{
+			name:   "allOf",
+			schema: AllOf{Schemas: []Schema{String{MinLength: 1}, String{MaxLength: 3}}},
+			want:   `{"allOf":[{"type":"string","minLength":1},{"type":"string","maxLength":3}]}`,
+		},
+		{
+			name:   "not",
+			schema: Not{Schema: Null{}},
+			want:   `{"not":{"type":"null"}}`,
+		},
+		{
+			name:    "oneOf without schemas",
+			schema:  OneOf{},
+			wantErr: true,
+		},
+		{
+			name:    "anyOf without schemas",
+			schema:  AnyOf{},
+			wantErr: true,
+		},
+		{
+			name:    "allOf without schemas",
+			schema:  AllOf{},
+			wantErr: true,
+		},
+		{
+			name:    "not without schema",
+			schema:  Not{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.schema.MarshalJSON()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			assertJSONEqual(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestAllOf_Violations(t *testing.T) {
+	schema := Object{
+		Properties: map[string]Schema{
+			"name": AllOf{Schemas: []Schema{String{MinLength: 3}, String{Pattern: "^x"}}},
+		},
+	}
+
+	err := schema.Validate(json.RawMessage(`{"name": "a"}`))
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Validate() error = %v, want *ValidationError", err)
+	}
+
+	want := []Violation{
+		{Location: "/name", Keyword: "minLength", Message: "string is too short"},
+		{Location: "/name", Keyword: "pattern", Message: "string does not match pattern ^x"},
+	}
+	if !reflect.DeepEqual(validationErr.Violations, want) {
+		t.Errorf("Violations = %+v, want %+v", validationErr.Violations, want)
+	}
+}
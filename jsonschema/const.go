@@ -19,13 +19,13 @@ func (s Const) Validate(v json.RawMessage) error {
 		return fmt.Errorf("unmarshal: %w", err)
 	}
 
-	return s.validate(m)
+	return validateValue(s, m)
 }
 
 // validate validates the const against the JSON schema.
-func (s Const) validate(v any) error {
+func (s Const) validate(c *validationContext, location string, v any) []Violation {
 	if !reflect.DeepEqual(s.Value, v) {
-		return fmt.Errorf("value does not match const value")
+		return []Violation{newViolation(location, "const", "value does not match const value")}
 	}
 
 	return nil
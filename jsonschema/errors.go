@@ -0,0 +1,82 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Violation is a violation of a schema keyword by a value.
+type Violation struct {
+	// Location is the JSON Pointer to the invalid value in the instance, e.g. "/items/3/name".
+	// Location is "" for the instance itself.
+	Location string `json:"location"`
+	// Keyword is the violated keyword of the schema, e.g. "minLength".
+	Keyword string `json:"keyword"`
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+// String returns the message prefixed with the location, e.g. "/items/3/name: value is not a string".
+func (v Violation) String() string {
+	if v.Location == "" {
+		return v.Message
+	}
+	return v.Location + ": " + v.Message
+}
+
+// ValidationError is the error returned by Validate when the value doesn't match the schema.
+// It reports all the violations found, in the order of the schema and the value:
+// the properties of an object are visited in the lexical order of their names, and the items of an array in their order.
+type ValidationError struct {
+	Violations []Violation
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	strs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		strs[i] = v.String()
+	}
+	return strings.Join(strs, "; ")
+}
+
+// newViolation returns a Violation with the formatted message.
+func newViolation(location, keyword, format string, args ...any) Violation {
+	return Violation{
+		Location: location,
+		Keyword:  keyword,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// validateValue validates the decoded value against the schema as the root of the document.
+// validateValue returns a *ValidationError if the value doesn't match the schema.
+func validateValue(s Schema, v any) error {
+	violations := s.validate(newValidationContext(s), "", v)
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: violations}
+}
+
+// relativeMessages joins the messages of the violations found in the value at location,
+// with their locations made relative to it.
+func relativeMessages(location string, violations []Violation) string {
+	strs := make([]string, len(violations))
+	for i, v := range violations {
+		v.Location = strings.TrimPrefix(v.Location, location)
+		strs[i] = v.String()
+	}
+	return strings.Join(strs, ", ")
+}
+
+// propertyLocation returns the location of the property of the object at location.
+func propertyLocation(location, name string) string {
+	return location + "/" + escapePointerToken(name)
+}
+
+// itemLocation returns the location of the item of the array at location.
+func itemLocation(location string, i int) string {
+	return location + "/" + strconv.Itoa(i)
+}
@@ -0,0 +1,77 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidationError(t *testing.T) {
+	schema := Object{
+		Properties: map[string]Schema{
+			"name":  String{MinLength: 3, Pattern: "^[a-z]+$"},
+			"count": Integer{Minimum: ptr(int64(0))},
+			"items": Array{
+				MaxItems: 2,
+				Items: Object{
+					Properties: map[string]Schema{
+						"id":  Integer{},
+						"a/b": Boolean{},
+					},
+					Required: []string{"id"},
+				},
+			},
+			"tags": Map{AdditionalProperties: String{}},
+		},
+		Required: []string{"name", "count"},
+	}
+
+	input := json.RawMessage(`{
+		"name": "A",
+		"items": [{"id": 1}, {"a/b": "yes"}, {"id": 1.5, "extra": true}],
+		"tags": {"z": "ok", "b": 1, "a": false},
+		"unknown": null
+	}`)
+
+	want := []Violation{
+		{Location: "", Keyword: "required", Message: "required property count not found"},
+		{Location: "", Keyword: "additionalProperties", Message: "unexpected property unknown"},
+		{Location: "/items", Keyword: "maxItems", Message: "array has too many items"},
+		{Location: "/items/1", Keyword: "required", Message: "required property id not found"},
+		{Location: "/items/1/a~1b", Keyword: "type", Message: "value is not a boolean"},
+		{Location: "/items/2", Keyword: "additionalProperties", Message: "unexpected property extra"},
+		{Location: "/items/2/id", Keyword: "type", Message: "value is not an integer"},
+		{Location: "/name", Keyword: "minLength", Message: "string is too short"},
+		{Location: "/name", Keyword: "pattern", Message: "string does not match pattern ^[a-z]+$"},
+		{Location: "/tags/a", Keyword: "type", Message: "value is not a string"},
+		{Location: "/tags/b", Keyword: "type", Message: "value is not a string"},
+	}
+
+	// The violations must be reported in the same order every time
+	for range 10 {
+		err := schema.Validate(input)
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("Validate() error = %v, want *ValidationError", err)
+		}
+		if !reflect.DeepEqual(validationErr.Violations, want) {
+			t.Fatalf("Violations = %+v, want %+v", validationErr.Violations, want)
+		}
+	}
+}
+
+func TestValidationError_Error(t *testing.T) {
+	err := &ValidationError{
+		Violations: []Violation{
+			{Location: "", Keyword: "required", Message: "required property a not found"},
+			{Location: "/b/0", Keyword: "type", Message: "value is not a string"},
+		},
+	}
+
+	want := "required property a not found; /b/0: value is not a string"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
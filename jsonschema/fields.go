@@ -0,0 +1,184 @@
+package jsonschema
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// structField is a field of a struct that is encoded as a property of the object by encoding/json.
+type structField struct {
+	// name is the name of the property
+	name string
+	// tagged reports whether the name is given by the json tag
+	tagged bool
+	// index is the index sequence of the field, as in reflect.Type.FieldByIndex
+	index []int
+	typ   reflect.Type
+	tag   SchemaTag
+	// quoted reports whether the value is encoded in a string by the ",string" option
+	quoted bool
+	// inlined reports whether the field is promoted through a struct field tagged with jsonschema:"inline",
+	// which encoding/json doesn't decode as a property of t
+	inlined bool
+}
+
+// structFields returns the fields of t encoded as properties, following the rules of encoding/json:
+//   - unexported fields and fields tagged with json:"-" are skipped,
+//   - the fields of an embedded struct without a JSON name are promoted to t,
+//     as well as the fields of a struct field tagged with jsonschema:"inline",
+//   - among the fields of the same name, the least nested one wins, then the one with a JSON name,
+//     and the fields are all dropped if it's still ambiguous.
+//
+// The fields are sorted by their index sequence.
+// structFields also returns the map field tagged with jsonschema:"inline", which holds the additional properties.
+func structFields(t reflect.Type) ([]structField, *structField, error) {
+	type scan struct {
+		typ     reflect.Type
+		index   []int
+		inlined bool
+	}
+
+	var candidates []structField
+	var inlineMaps []structField
+	visited := map[reflect.Type]bool{}
+	next := []scan{{typ: t}}
+	for len(next) > 0 {
+		current := next
+		next = nil
+		for _, s := range current {
+			if visited[s.typ] {
+				continue
+			}
+			visited[s.typ] = true
+
+			for i := range s.typ.NumField() {
+				sf := s.typ.Field(i)
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+
+				if sf.Anonymous {
+					// The exported fields of an embedded struct are promoted even if the struct type is unexported
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				index := append(slices.Clone(s.index), i)
+				tag := ParseSchemaTag(sf.Tag)
+				jsonTag := sf.Tag.Get("json")
+				if jsonTag == "-" && !tag.Inline {
+					continue
+				}
+				name, opts, _ := strings.Cut(jsonTag, ",")
+				if jsonTag == "-" {
+					name = ""
+				}
+
+				if tag.Inline {
+					switch ft.Kind() {
+					case reflect.Struct:
+						next = append(next, scan{typ: ft, index: index, inlined: true})
+					case reflect.Map:
+						inlineMaps = append(inlineMaps, structField{name: sf.Name, index: index, typ: ft, tag: tag})
+					default:
+						return nil, nil, fmt.Errorf("field %s: inline is supported only for struct and map", sf.Name)
+					}
+					continue
+				}
+
+				if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+					next = append(next, scan{typ: ft, index: index, inlined: s.inlined})
+					continue
+				}
+
+				f := structField{
+					name:    name,
+					tagged:  name != "",
+					index:   index,
+					typ:     sf.Type,
+					tag:     tag,
+					inlined: s.inlined,
+				}
+				if f.name == "" {
+					f.name = sf.Name
+				}
+				if slices.Contains(strings.Split(opts, ","), "string") {
+					switch ft.Kind() {
+					case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+						reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+						reflect.Float32, reflect.Float64:
+						f.quoted = true
+					}
+				}
+				candidates = append(candidates, f)
+			}
+		}
+	}
+
+	// Resolve the fields of the same name
+	slices.SortStableFunc(candidates, func(a, b structField) int {
+		return cmp.Or(
+			strings.Compare(a.name, b.name),
+			cmp.Compare(len(a.index), len(b.index)),
+			compareTagged(a, b),
+		)
+	})
+	var fields []structField
+	for group := range chunkByName(candidates) {
+		dominant := group[0]
+		if len(group) > 1 && len(group[1].index) == len(dominant.index) && group[1].tagged == dominant.tagged {
+			continue
+		}
+		fields = append(fields, dominant)
+	}
+	slices.SortFunc(fields, func(a, b structField) int {
+		return slices.Compare(a.index, b.index)
+	})
+
+	var inlineMap *structField
+	switch len(inlineMaps) {
+	case 0:
+	case 1:
+		inlineMap = &inlineMaps[0]
+	default:
+		return nil, nil, fmt.Errorf("field %s: more than one inline map", inlineMaps[1].name)
+	}
+
+	return fields, inlineMap, nil
+}
+
+// compareTagged orders the field with a JSON name first.
+func compareTagged(a, b structField) int {
+	switch {
+	case a.tagged == b.tagged:
+		return 0
+	case a.tagged:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// chunkByName yields the runs of the fields of the same name.
+func chunkByName(fields []structField) iter.Seq[[]structField] {
+	return func(yield func([]structField) bool) {
+		for len(fields) > 0 {
+			n := 1
+			for n < len(fields) && fields[n].name == fields[0].name {
+				n++
+			}
+			if !yield(fields[:n]) {
+				return
+			}
+			fields = fields[n:]
+		}
+	}
+}
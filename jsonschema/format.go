@@ -0,0 +1,204 @@
+package jsonschema
+
+import (
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FormatValidator validates a string of a format.
+// FormatValidator returns an error describing why the string is invalid.
+type FormatValidator func(s string) error
+
+var (
+	formatsMu sync.RWMutex
+	formats   = map[string]FormatValidator{
+		"date-time": validateDateTime,
+		"date":      validateDate,
+		"time":      validateTime,
+		"email":     validateEmail,
+		"uri":       validateURI,
+		"uuid":      validateUUID,
+		"ipv4":      validateIPv4,
+		"ipv6":      validateIPv6,
+		"hostname":  validateHostname,
+	}
+)
+
+// RegisterFormat registers the validator for the format used by String.Format.
+// Registering a format that is already registered replaces the validator,
+// including the built-in ones: date-time, date, time, email, uri, uuid, ipv4, ipv6 and hostname.
+// RegisterFormat is safe for concurrent use.
+func RegisterFormat(name string, f FormatValidator) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	formats[name] = f
+}
+
+// lookupFormat returns the validator for the format.
+func lookupFormat(name string) (FormatValidator, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	f, ok := formats[name]
+	return f, ok
+}
+
+// validateDateTime validates a date-time of RFC 3339.
+func validateDateTime(s string) error {
+	// RFC 3339 allows lowercase "t" and "z", which time.Parse doesn't accept
+	if _, err := time.Parse(time.RFC3339Nano, strings.ToUpper(s)); err != nil {
+		return fmt.Errorf("not an RFC 3339 date-time")
+	}
+	return nil
+}
+
+// validateDate validates a full-date of RFC 3339.
+func validateDate(s string) error {
+	if _, err := time.Parse(time.DateOnly, s); err != nil {
+		return fmt.Errorf("not an RFC 3339 full-date")
+	}
+	return nil
+}
+
+// validateTime validates a full-time of RFC 3339.
+func validateTime(s string) error {
+	if _, err := time.Parse("15:04:05.999999999Z07:00", strings.ToUpper(s)); err != nil {
+		return fmt.Errorf("not an RFC 3339 full-time")
+	}
+	return nil
+}
+
+// validateEmail validates an email address without a display name.
+func validateEmail(s string) error {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Name != "" || addr.Address != s {
+		return fmt.Errorf("not an email address")
+	}
+	return nil
+}
+
+// validateURI validates an absolute URI.
+func validateURI(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return fmt.Errorf("not a URI: %w", err)
+	}
+	if !u.IsAbs() {
+		return fmt.Errorf("not an absolute URI")
+	}
+	return nil
+}
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validateUUID validates a UUID of RFC 4122.
+func validateUUID(s string) error {
+	if !uuidRegexp.MatchString(s) {
+		return fmt.Errorf("not a UUID")
+	}
+	return nil
+}
+
+// validateIPv4 validates an IPv4 address in dotted-quad notation.
+func validateIPv4(s string) error {
+	addr, err := netip.ParseAddr(s)
+	if err != nil || !addr.Is4() {
+		return fmt.Errorf("not an IPv4 address")
+	}
+	return nil
+}
+
+// validateIPv6 validates an IPv6 address without a zone.
+func validateIPv6(s string) error {
+	addr, err := netip.ParseAddr(s)
+	if err != nil || !addr.Is6() || addr.Zone() != "" {
+		return fmt.Errorf("not an IPv6 address")
+	}
+	return nil
+}
+
+// validateHostname validates a hostname of RFC 1123.
+func validateHostname(s string) error {
+	name := strings.TrimSuffix(s, ".")
+	if name == "" || len(name) > 253 {
+		return fmt.Errorf("not a hostname")
+	}
+	for label := range strings.SplitSeq(name, ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("invalid label %q", label)
+		}
+		for _, c := range []byte(label) {
+			if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-') {
+				return fmt.Errorf("invalid label %q", label)
+			}
+		}
+	}
+	return nil
+}
+
+// patterns caches the compiled patterns of String.Pattern, so that each pattern is compiled once.
+var patterns sync.Map // map[string]*regexp.Regexp
+
+// compilePattern compiles an ECMA-262 regular expression.
+// The escapes for code points, "\uXXXX" and "\u{X...}", are translated into the RE2 syntax.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(translatePattern(pattern))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+	}
+	patterns.Store(pattern, re)
+	return re, nil
+}
+
+// translatePattern translates the ECMA-262 escapes that RE2 doesn't support into the RE2 syntax.
+func translatePattern(pattern string) string {
+	var sb strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c != '\\' || i+1 >= len(pattern) {
+			sb.WriteByte(c)
+			continue
+		}
+		if pattern[i+1] != 'u' {
+			sb.WriteString(pattern[i : i+2])
+			i++
+			continue
+		}
+
+		rest := pattern[i+2:]
+		switch {
+		case strings.HasPrefix(rest, "{") && strings.Contains(rest, "}"):
+			end := strings.IndexByte(rest, '}')
+			sb.WriteString(`\x` + rest[:end+1])
+			i += 2 + end
+		case len(rest) >= 4 && isHexString(rest[:4]):
+			sb.WriteString(`\x{` + rest[:4] + `}`)
+			i += 5
+		default:
+			sb.WriteString(`\u`)
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// isHexString reports whether s consists of hexadecimal digits.
+func isHexString(s string) bool {
+	for _, c := range []byte(s) {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package jsonschema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestString_ValidateFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		value   string
+		wantErr bool
+	}{
+		{"date-time", "2024-01-02T15:04:05Z", false},
+		{"date-time", "2024-01-02t15:04:05.123+09:00", false},
+		{"date-time", "2024-01-02 15:04:05", true},
+		{"date", "2024-02-29", false},
+		{"date", "2023-02-29", true},
+		{"time", "15:04:05Z", false},
+		{"time", "15:04:05.5-07:00", false},
+		{"time", "15:04", true},
+		{"email", "user@example.com", false},
+		{"email", "User <user@example.com>", true},
+		{"email", "user", true},
+		{"uri", "https://example.com/path?q=1", false},
+		{"uri", "/relative/path", true},
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000", false},
+		{"uuid", "123e4567e89b12d3a456426614174000", true},
+		{"ipv4", "192.0.2.1", false},
+		{"ipv4", "2001:db8::1", true},
+		{"ipv6", "2001:db8::1", false},
+		{"ipv6", "192.0.2.1", true},
+		{"hostname", "example.com", false},
+		{"hostname", "-example.com", true},
+		{"hostname", "exa_mple.com", true},
+		{"unknown", "anything", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.value, func(t *testing.T) {
+			s := String{Format: tt.format}
+			err := validateValue(s, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat("even-length", func(s string) error {
+		if len(s)%2 != 0 {
+			return errors.New("length is odd")
+		}
+		return nil
+	})
+
+	s := String{Format: "even-length"}
+	if err := validateValue(s, "ab"); err != nil {
+		t.Errorf("validate() error = %v, want nil", err)
+	}
+	err := validateValue(s, "abc")
+	if err == nil || !strings.Contains(err.Error(), "length is odd") {
+		t.Errorf("validate() error = %v, want error containing %q", err, "length is odd")
+	}
+}
+
+func TestString_ValidatePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		value   string
+		wantErr bool
+	}{
+		{"match", `^[a-z]+$`, "abc", false},
+		{"mismatch", `^[a-z]+$`, "abc1", true},
+		{"unanchored", `[0-9]`, "abc1", false},
+		{"unicode escape", `^\u00e9$`, "é", false},
+		{"unicode code point escape", `^\u{1F600}$`, "😀", false},
+		{"escaped backslash", `^\\u0041$`, `\u0041`, false},
+		{"invalid pattern", `(?=a)`, "a", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := String{Pattern: tt.pattern}
+			err := validateValue(s, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package jsonschema
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 )
 
 // Map is a JSON schema object that maps keys to values.
@@ -20,22 +22,21 @@ func (s Map) Validate(v json.RawMessage) error {
 		return fmt.Errorf("unmarshal: %w", err)
 	}
 
-	return s.validate(m)
+	return validateValue(s, m)
 }
 
-func (s Map) validate(v any) error {
+func (s Map) validate(c *validationContext, location string, v any) []Violation {
 	m, ok := v.(map[string]any)
 	if !ok {
-		return fmt.Errorf("value is not a object")
+		return []Violation{newViolation(location, "type", "value is not a object")}
 	}
 
-	for k, v := range m {
-		if err := s.AdditionalProperties.validate(v); err != nil {
-			return fmt.Errorf("validate value %s: %w", k, err)
-		}
+	var violations []Violation
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		violations = append(violations, s.AdditionalProperties.validate(c, propertyLocation(location, k), m[k])...)
 	}
 
-	return nil
+	return violations
 }
 
 func (s Map) MarshalJSON() ([]byte, error) {
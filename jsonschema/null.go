@@ -17,13 +17,13 @@ func (s Null) Validate(v json.RawMessage) error {
 		return fmt.Errorf("unmarshal: %w", err)
 	}
 
-	return s.validate(m)
+	return validateValue(s, m)
 }
 
 // validate validates the null against the JSON schema.
-func (s Null) validate(v any) error {
+func (s Null) validate(c *validationContext, location string, v any) []Violation {
 	if v != nil {
-		return fmt.Errorf("value is not null")
+		return []Violation{newViolation(location, "type", "value is not null")}
 	}
 
 	return nil
@@ -3,6 +3,7 @@ package jsonschema
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 )
 
 // Number is a JSON schema for a number.
@@ -12,6 +13,9 @@ type Number struct {
 	Maximum          *float64 `json:"maximum,omitempty,omitzero"`
 	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty,omitzero"`
 	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty,omitzero"`
+	// Enum is the list of the allowed values, or nil to allow any number.
+	// An empty non-nil Enum allows no number.
+	Enum []float64 `json:"enum,omitzero"`
 }
 
 func (s Number) Validate(v json.RawMessage) error {
@@ -20,32 +24,38 @@ func (s Number) Validate(v json.RawMessage) error {
 		return fmt.Errorf("unmarshal: %w", err)
 	}
 
-	return s.validate(n)
+	return validateValue(s, n)
 }
 
-func (s Number) validate(v any) error {
+func (s Number) validate(c *validationContext, location string, v any) []Violation {
 	n, ok := v.(float64)
 	if !ok {
-		return fmt.Errorf("value is not a number")
+		return []Violation{newViolation(location, "type", "value is not a number")}
 	}
 
+	var violations []Violation
+
 	if s.Minimum != nil && n < *s.Minimum {
-		return fmt.Errorf("number is less than minimum")
+		violations = append(violations, newViolation(location, "minimum", "number is less than minimum"))
 	}
 
 	if s.Maximum != nil && n > *s.Maximum {
-		return fmt.Errorf("number is greater than maximum")
+		violations = append(violations, newViolation(location, "maximum", "number is greater than maximum"))
 	}
 
 	if s.ExclusiveMinimum != nil && n <= *s.ExclusiveMinimum {
-		return fmt.Errorf("number is less than or equal to exclusive minimum")
+		violations = append(violations, newViolation(location, "exclusiveMinimum", "number is less than or equal to exclusive minimum"))
 	}
 
 	if s.ExclusiveMaximum != nil && n >= *s.ExclusiveMaximum {
-		return fmt.Errorf("number is greater than or equal to exclusive maximum")
+		violations = append(violations, newViolation(location, "exclusiveMaximum", "number is greater than or equal to exclusive maximum"))
+	}
+
+	if s.Enum != nil && !slices.Contains(s.Enum, n) {
+		violations = append(violations, newViolation(location, "enum", "value is not one of the enum values"))
 	}
 
-	return nil
+	return violations
 }
 
 func (s Number) MarshalJSON() ([]byte, error) {
@@ -67,6 +77,9 @@ type Integer struct {
 	Maximum          *int64 `json:"maximum,omitempty,omitzero"`
 	ExclusiveMinimum *int64 `json:"exclusiveMinimum,omitempty,omitzero"`
 	ExclusiveMaximum *int64 `json:"exclusiveMaximum,omitempty,omitzero"`
+	// Enum is the list of the allowed values, or nil to allow any integer.
+	// An empty non-nil Enum allows no integer.
+	Enum []int64 `json:"enum,omitzero"`
 }
 
 func (s Integer) Validate(v json.RawMessage) error {
@@ -75,38 +88,44 @@ func (s Integer) Validate(v json.RawMessage) error {
 		return fmt.Errorf("unmarshal: %w", err)
 	}
 
-	return s.validate(n)
+	return validateValue(s, n)
 }
 
-func (s Integer) validate(v any) error {
+func (s Integer) validate(c *validationContext, location string, v any) []Violation {
 	n, ok := v.(float64)
 	if !ok {
-		return fmt.Errorf("value is not a number")
+		return []Violation{newViolation(location, "type", "value is not a number")}
 	}
 
 	if float64(int64(n)) != n {
-		return fmt.Errorf("value is not an integer")
+		return []Violation{newViolation(location, "type", "value is not an integer")}
 	}
 
 	i := int64(n)
 
+	var violations []Violation
+
 	if s.Minimum != nil && i < *s.Minimum {
-		return fmt.Errorf("number is less than minimum")
+		violations = append(violations, newViolation(location, "minimum", "number is less than minimum"))
 	}
 
 	if s.Maximum != nil && i > *s.Maximum {
-		return fmt.Errorf("number is greater than maximum")
+		violations = append(violations, newViolation(location, "maximum", "number is greater than maximum"))
 	}
 
 	if s.ExclusiveMinimum != nil && i <= *s.ExclusiveMinimum {
-		return fmt.Errorf("number is less than or equal to exclusive minimum")
+		violations = append(violations, newViolation(location, "exclusiveMinimum", "number is less than or equal to exclusive minimum"))
 	}
 
 	if s.ExclusiveMaximum != nil && i >= *s.ExclusiveMaximum {
-		return fmt.Errorf("number is greater than or equal to exclusive maximum")
+		violations = append(violations, newViolation(location, "exclusiveMaximum", "number is greater than or equal to exclusive maximum"))
+	}
+
+	if s.Enum != nil && !slices.Contains(s.Enum, i) {
+		violations = append(violations, newViolation(location, "enum", "value is not one of the enum values"))
 	}
 
-	return nil
+	return violations
 }
 
 func (s Integer) MarshalJSON() ([]byte, error) {
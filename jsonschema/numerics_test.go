@@ -16,6 +16,17 @@ func TestNumber_Validate(t *testing.T) {
 		value   json.RawMessage
 		wantErr bool
 	}{
+		{
+			name:   "number in enum",
+			schema: Number{Enum: []float64{0.5, 1.5}},
+			value:  json.RawMessage(`1.5`),
+		},
+		{
+			name:    "number not in enum",
+			schema:  Number{Enum: []float64{0.5, 1.5}},
+			value:   json.RawMessage(`1`),
+			wantErr: true,
+		},
 		{
 			name:   "valid number",
 			schema: Number{},
@@ -100,6 +111,17 @@ func TestInteger_Validate(t *testing.T) {
 		value   json.RawMessage
 		wantErr bool
 	}{
+		{
+			name:   "integer in enum",
+			schema: Integer{Enum: []int64{1, 2, 3}},
+			value:  json.RawMessage(`2`),
+		},
+		{
+			name:    "integer not in enum",
+			schema:  Integer{Enum: []int64{1, 2, 3}},
+			value:   json.RawMessage(`4`),
+			wantErr: true,
+		},
 		{
 			name:   "valid integer",
 			schema: Integer{},
@@ -190,6 +212,11 @@ func TestNumber_MarshalJSON(t *testing.T) {
 		want    string
 		wantErr bool
 	}{
+		{
+			name:   "with enum",
+			schema: Number{Enum: []float64{0.5, 1.5}},
+			want:   `{"type":"number","enum":[0.5,1.5]}`,
+		},
 		{
 			name:   "empty schema",
 			schema: Number{},
@@ -3,6 +3,9 @@ package jsonschema
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"regexp"
+	"slices"
 )
 
 // Object is a JSON schema object.
@@ -10,6 +13,20 @@ type Object struct {
 	Description string            `json:"description,omitempty"`
 	Properties  map[string]Schema `json:"properties"`
 	Required    []string          `json:"required,omitempty,omitzero"`
+	// AdditionalProperties is the schema of the properties declared neither in Properties nor in PatternProperties.
+	// nil rejects such properties and Any{} accepts any of them, as false and true do in JSON schema.
+	AdditionalProperties Schema `json:"-"`
+	// PatternProperties maps an ECMA-262 regular expression to the schema of the properties whose names match it.
+	PatternProperties map[string]Schema `json:"patternProperties,omitempty"`
+	// PropertyNames is the schema that the names of the properties must match, usually a String.
+	PropertyNames Schema `json:"propertyNames,omitempty"`
+	MinProperties int    `json:"minProperties,omitempty"`
+	MaxProperties int    `json:"maxProperties,omitempty"`
+	// DependentRequired maps a property to the properties required when it's present.
+	DependentRequired map[string][]string `json:"dependentRequired,omitempty"`
+	// Defs are the definitions referred by Ref, e.g. "#/$defs/name".
+	// The definitions are resolved only when the Object is the root of the document.
+	Defs map[string]Schema `json:"$defs,omitempty"`
 }
 
 // MarshalJSON implements the json.Marshaler interface.
@@ -20,15 +37,33 @@ func (o Object) MarshalJSON() ([]byte, error) {
 		}
 	}
 
+	for pattern := range o.PatternProperties {
+		if _, err := compilePattern(pattern); err != nil {
+			return nil, err
+		}
+	}
+
+	var additionalProperties any = false
+	switch s := o.AdditionalProperties.(type) {
+	case nil:
+	case Any:
+		additionalProperties = s
+		if s == (Any{}) {
+			additionalProperties = true
+		}
+	default:
+		additionalProperties = s
+	}
+
 	type objectSchema Object
 
 	return json.Marshal(struct {
 		Type                 string `json:"type"`
-		AdditionalProperties bool   `json:"additionalProperties"`
+		AdditionalProperties any    `json:"additionalProperties"`
 		objectSchema
 	}{
 		Type:                 "object",
-		AdditionalProperties: false, // we set this to false for simplicity
+		AdditionalProperties: additionalProperties,
 		objectSchema:         objectSchema(o),
 	})
 }
@@ -40,37 +75,107 @@ func (o Object) Validate(v json.RawMessage) error {
 		return fmt.Errorf("unmarshal: %w", err)
 	}
 
-	return o.validate(m)
+	return validateValue(o, m)
 }
 
 // validate validates the object against the JSON schema.
-func (o Object) validate(v any) error {
+// The violations of the object itself are reported before the ones of the property values.
+func (o Object) validate(c *validationContext, location string, v any) []Violation {
 	m, ok := v.(map[string]any)
 	if !ok {
-		return fmt.Errorf("object is not a map")
+		return []Violation{newViolation(location, "type", "object is not a map")}
 	}
 
+	var violations []Violation
+
 	for _, r := range o.Required {
 		if _, ok := m[r]; !ok {
-			return fmt.Errorf("required property %s not found", r)
+			violations = append(violations, newViolation(location, "required", "required property %s not found", r))
 		}
 	}
 
-	for k := range m {
-		if _, ok := o.Properties[k]; !ok {
-			return fmt.Errorf("unexpected property %s", k)
+	for _, k := range slices.Sorted(maps.Keys(o.DependentRequired)) {
+		if _, ok := m[k]; !ok {
+			continue
+		}
+		for _, r := range o.DependentRequired[k] {
+			if _, ok := m[r]; !ok {
+				violations = append(violations, newViolation(location, "dependentRequired", "property %s is required by property %s", r, k))
+			}
 		}
 	}
 
-	for k, v := range o.Properties {
-		if _, ok := m[k]; !ok {
+	if o.MinProperties > 0 && len(m) < o.MinProperties {
+		violations = append(violations, newViolation(location, "minProperties", "object has too few properties"))
+	}
+
+	if o.MaxProperties > 0 && len(m) > o.MaxProperties {
+		violations = append(violations, newViolation(location, "maxProperties", "object has too many properties"))
+	}
+
+	patterns, err := o.compilePatterns()
+	if err != nil {
+		return append(violations, newViolation(location, "patternProperties", "%s", err))
+	}
+
+	keys := slices.Sorted(maps.Keys(m))
+	for _, k := range keys {
+		if o.PropertyNames != nil {
+			if nameViolations := o.PropertyNames.validate(c, location, k); len(nameViolations) > 0 {
+				violations = append(violations, newViolation(location, "propertyNames", "invalid property name %s: %s", k, relativeMessages(location, nameViolations)))
+			}
+		}
+
+		if o.AdditionalProperties != nil {
 			continue
 		}
+		if _, ok := o.Properties[k]; ok {
+			continue
+		}
+		if !slices.ContainsFunc(patterns, func(p patternProperty) bool { return p.re.MatchString(k) }) {
+			violations = append(violations, newViolation(location, "additionalProperties", "unexpected property %s", k))
+		}
+	}
+
+	for _, k := range keys {
+		propertyLocation := propertyLocation(location, k)
+		matched := false
+
+		if schema, ok := o.Properties[k]; ok {
+			matched = true
+			violations = append(violations, schema.validate(c, propertyLocation, m[k])...)
+		}
 
-		if err := v.validate(m[k]); err != nil {
-			return fmt.Errorf("property %s: %w", k, err)
+		for _, p := range patterns {
+			if p.re.MatchString(k) {
+				matched = true
+				violations = append(violations, p.schema.validate(c, propertyLocation, m[k])...)
+			}
+		}
+
+		if !matched && o.AdditionalProperties != nil {
+			violations = append(violations, o.AdditionalProperties.validate(c, propertyLocation, m[k])...)
 		}
 	}
 
-	return nil
+	return violations
+}
+
+// patternProperty is a compiled pattern of PatternProperties.
+type patternProperty struct {
+	re     *regexp.Regexp
+	schema Schema
+}
+
+// compilePatterns compiles the patterns of PatternProperties, in the lexical order of the patterns.
+func (o Object) compilePatterns() ([]patternProperty, error) {
+	var patterns []patternProperty
+	for _, pattern := range slices.Sorted(maps.Keys(o.PatternProperties)) {
+		re, err := compilePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, patternProperty{re: re, schema: o.PatternProperties[pattern]})
+	}
+	return patterns, nil
 }
@@ -22,6 +22,34 @@ func TestObject_MarshalJSON(t *testing.T) {
 			want:    `{"additionalProperties":false,"properties":{"prop1":{"type":"string"}},"required":["prop1"],"type":"object"}`,
 			wantErr: false,
 		},
+		{
+			name: "open object",
+			object: Object{
+				Properties:           map[string]Schema{},
+				AdditionalProperties: Any{},
+			},
+			want: `{"additionalProperties":true,"properties":{},"type":"object"}`,
+		},
+		{
+			name: "object with property keywords",
+			object: Object{
+				Properties:           map[string]Schema{"a": String{}},
+				AdditionalProperties: Integer{},
+				PatternProperties:    map[string]Schema{"^x-": String{}},
+				PropertyNames:        String{MaxLength: 8},
+				MinProperties:        1,
+				MaxProperties:        3,
+				DependentRequired:    map[string][]string{"a": {"b"}},
+			},
+			want: `{"additionalProperties":{"type":"integer"},"dependentRequired":{"a":["b"]},"maxProperties":3,"minProperties":1,"patternProperties":{"^x-":{"type":"string"}},"properties":{"a":{"type":"string"}},"propertyNames":{"maxLength":8,"type":"string"},"type":"object"}`,
+		},
+		{
+			name: "invalid pattern property",
+			object: Object{
+				PatternProperties: map[string]Schema{"(?=a)": String{}},
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing required property",
 			object: Object{
@@ -92,6 +120,118 @@ func TestObject_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "additional properties allowed",
+			object: Object{
+				Properties:           map[string]Schema{"prop1": String{}},
+				AdditionalProperties: Any{},
+			},
+			value: map[string]any{
+				"prop1": "value1",
+				"prop2": 2.0,
+			},
+		},
+		{
+			name: "additional property with schema",
+			object: Object{
+				Properties:           map[string]Schema{"prop1": String{}},
+				AdditionalProperties: Integer{},
+			},
+			value: map[string]any{
+				"prop1": "value1",
+				"prop2": "value2",
+			},
+			wantErr: true,
+		},
+		{
+			name: "pattern property",
+			object: Object{
+				Properties:        map[string]Schema{},
+				PatternProperties: map[string]Schema{"^x-": String{}},
+			},
+			value: map[string]any{
+				"x-trace": "id",
+			},
+		},
+		{
+			name: "invalid pattern property value",
+			object: Object{
+				Properties:           map[string]Schema{},
+				PatternProperties:    map[string]Schema{"^x-": String{}},
+				AdditionalProperties: Any{},
+			},
+			value: map[string]any{
+				"x-trace": 1.0,
+			},
+			wantErr: true,
+		},
+		{
+			name: "property not matching pattern",
+			object: Object{
+				Properties:        map[string]Schema{},
+				PatternProperties: map[string]Schema{"^x-": String{}},
+			},
+			value: map[string]any{
+				"trace": "id",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid property name",
+			object: Object{
+				AdditionalProperties: Any{},
+				PropertyNames:        String{Pattern: "^[a-z]+$"},
+			},
+			value: map[string]any{
+				"Name": "value",
+			},
+			wantErr: true,
+		},
+		{
+			name: "too few properties",
+			object: Object{
+				AdditionalProperties: Any{},
+				MinProperties:        2,
+			},
+			value: map[string]any{
+				"a": 1.0,
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many properties",
+			object: Object{
+				AdditionalProperties: Any{},
+				MaxProperties:        1,
+			},
+			value: map[string]any{
+				"a": 1.0,
+				"b": 2.0,
+			},
+			wantErr: true,
+		},
+		{
+			name: "dependent required satisfied",
+			object: Object{
+				Properties:        map[string]Schema{"card": String{}, "address": String{}},
+				DependentRequired: map[string][]string{"card": {"address"}},
+			},
+			value: map[string]any{
+				"card":    "1234",
+				"address": "Tokyo",
+			},
+		},
+		{
+			name: "dependent required missing",
+			object: Object{
+				Properties:        map[string]Schema{"card": String{}, "address": String{}},
+				DependentRequired: map[string][]string{"card": {"address"}},
+			},
+			value: map[string]any{
+				"card": "1234",
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid property value",
 			object: Object{
@@ -109,7 +249,7 @@ func TestObject_Validate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := tt.object.validate(tt.value); (err != nil) != tt.wantErr {
+			if err := validateValue(tt.object, tt.value); (err != nil) != tt.wantErr {
 				t.Errorf("Object.Validate() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -0,0 +1,792 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Parse parses a JSON schema document into a Schema.
+//
+// The schema is built from the keywords supported by the types of this package, and the other keywords are ignored.
+//   - "type" selects the type, e.g. String for "string". An object constrained only by an "additionalProperties" schema
+//     is a Map, and otherwise an Object, which accepts additional properties unless "additionalProperties" is false.
+//     So {"type": "object"}, the schema of a tool without arguments, is parsed as Object{AdditionalProperties: Any{}}.
+//     A list of types, e.g. ["string", "null"], is parsed as AnyOf with a schema for each type.
+//   - A schema without "type" is inferred as an object if it has "properties", or an array if it has "items".
+//     Otherwise, the keywords specific to a type, e.g. "minLength", are ignored.
+//   - "enum" is the Enum of String, Integer and Number, without the values of other types.
+//     Otherwise, e.g. without "type" or with "type": "boolean", it's parsed as AnyOf with a Const for each value.
+//   - The bounds of an integer that aren't integers are rounded to the inclusive bounds of the integers within them,
+//     and the boolean "exclusiveMinimum" and "exclusiveMaximum" of draft-04 make "minimum" and "maximum" exclusive.
+//   - "$ref", "const", "oneOf", "anyOf", "allOf" and "not" are parsed into their own types,
+//     which are combined with AllOf if a schema has more than one of them or "type" as well.
+//   - "$defs" of the root, or "definitions" of draft-07, are the definitions referred by "#/$defs/<name>"
+//     or "#/definitions/<name>", which are parsed as RefTo(name). They are the Defs of the root Object,
+//     or of an AllOf wrapping the root of any other type. A root schema that is only a reference to
+//     an object definition, e.g. {"$ref": "#/definitions/args", "definitions": {"args": {...}}}, is parsed as the definition.
+//     Parse returns an error for a reference that can't be resolved in the document, such as an external one.
+//   - The boolean schemas true and false, as well as the empty schema, are parsed as Any and Not{Schema: Any{}}.
+//
+// The schemas marshaled by MarshalJSON are parsed back into the same schemas.
+func Parse(data []byte) (Schema, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	// The definitions are parsed apart from the other keywords of the root, and attached to the root below
+	var defs map[string]Schema
+	if m, ok := v.(map[string]any); ok {
+		var err error
+		if defs, err = keywords(m).defs(); err != nil {
+			return nil, err
+		}
+		m = maps.Clone(m)
+		delete(m, "$defs")
+		delete(m, "definitions")
+		v = m
+	}
+
+	root, err := parseSchema(v)
+	if err != nil {
+		return nil, err
+	}
+	root = withDefs(root, defs)
+
+	if err := checkRefs(root); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// withDefs returns the root schema with the definitions of the document.
+func withDefs(root Schema, defs map[string]Schema) Schema {
+	switch r := root.(type) {
+	case Object:
+		r.Defs = defs
+		return r
+	case Map:
+		// An object with definitions isn't a map, as parseObject would parse it
+		if defs != nil {
+			return Object{Description: r.Description, AdditionalProperties: r.AdditionalProperties, Defs: defs}
+		}
+	case Ref:
+		// A root that is only a reference to an object definition is parsed as the definition
+		name, ok := strings.CutPrefix(r.Ref, defsPrefix)
+		if o, isObject := defs[unescapePointerToken(name)].(Object); ok && isObject {
+			o.Defs = defs
+			if r.Description != "" {
+				o.Description = r.Description
+			}
+			return o
+		}
+	case AllOf:
+		r.Defs = defs
+		return r
+	}
+
+	if len(defs) == 0 {
+		return root
+	}
+	return AllOf{Schemas: []Schema{root}, Defs: defs}
+}
+
+// checkRefs returns an error if a reference in the schema can't be resolved against the schema as the root.
+func checkRefs(root Schema) error {
+	c := newValidationContext(root)
+	var err error
+	walkSchema(root, func(s Schema) {
+		if ref, ok := s.(Ref); ok && err == nil {
+			if _, rerr := c.resolve(ref.Ref); rerr != nil {
+				err = fmt.Errorf("$ref: %w", rerr)
+			}
+		}
+	})
+	return err
+}
+
+// walkSchema calls f for the schema and the schemas nested in it.
+func walkSchema(s Schema, f func(Schema)) {
+	if s == nil {
+		return
+	}
+	f(s)
+
+	switch s := s.(type) {
+	case Array:
+		walkSchema(s.Items, f)
+	case Map:
+		walkSchema(s.AdditionalProperties, f)
+	case Object:
+		for _, m := range []map[string]Schema{s.Properties, s.PatternProperties, s.Defs} {
+			for _, key := range slices.Sorted(maps.Keys(m)) {
+				walkSchema(m[key], f)
+			}
+		}
+		walkSchema(s.AdditionalProperties, f)
+		walkSchema(s.PropertyNames, f)
+	case OneOf:
+		for _, s := range s.Schemas {
+			walkSchema(s, f)
+		}
+	case AnyOf:
+		for _, s := range s.Schemas {
+			walkSchema(s, f)
+		}
+	case AllOf:
+		for _, s := range s.Schemas {
+			walkSchema(s, f)
+		}
+		for _, key := range slices.Sorted(maps.Keys(s.Defs)) {
+			walkSchema(s.Defs[key], f)
+		}
+	case Not:
+		walkSchema(s.Schema, f)
+	}
+}
+
+// parseSchema parses a decoded JSON schema.
+func parseSchema(v any) (Schema, error) {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return Any{}, nil
+		}
+		return Not{Schema: Any{}}, nil
+	case map[string]any:
+		return keywords(v).parse()
+	default:
+		return nil, fmt.Errorf("schema must be an object or a boolean, got %T", v)
+	}
+}
+
+// keywords is a decoded JSON schema object.
+type keywords map[string]any
+
+// parse parses the keywords into a Schema.
+func (k keywords) parse() (Schema, error) {
+	description, err := k.string("description")
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []Schema
+
+	typed, err := k.parseType()
+	if err != nil {
+		return nil, err
+	}
+	if typed != nil {
+		parts = append(parts, typed)
+	}
+
+	if _, ok := k["$ref"]; ok {
+		ref, err := k.string("$ref")
+		if err != nil {
+			return nil, err
+		}
+		// The definitions of draft-07 are parsed as $defs
+		if name, ok := strings.CutPrefix(ref, definitionsPrefix); ok {
+			ref = defsPrefix + name
+		}
+		parts = append(parts, Ref{Ref: ref})
+	}
+
+	if value, ok := k["const"]; ok {
+		parts = append(parts, Const{Value: value})
+	}
+
+	// The enum of a type without its own Enum field is kept as Consts
+	if values, ok := k["enum"]; ok && !hasEnum(typed) {
+		values, ok := values.([]any)
+		if !ok {
+			return nil, fmt.Errorf("enum: must be an array")
+		}
+		consts := make([]Schema, len(values))
+		for i, value := range values {
+			consts[i] = Const{Value: value}
+		}
+		parts = append(parts, AnyOf{Schemas: consts})
+	}
+
+	for _, name := range []string{"oneOf", "anyOf", "allOf"} {
+		if _, ok := k[name]; !ok {
+			continue
+		}
+		schemas, err := k.schemas(name)
+		if err != nil {
+			return nil, err
+		}
+		switch name {
+		case "oneOf":
+			parts = append(parts, OneOf{Schemas: schemas})
+		case "anyOf":
+			parts = append(parts, AnyOf{Schemas: schemas})
+		case "allOf":
+			parts = append(parts, AllOf{Schemas: schemas})
+		}
+	}
+
+	if not, ok, err := k.schema("not"); err != nil {
+		return nil, err
+	} else if ok {
+		parts = append(parts, Not{Schema: not})
+	}
+
+	switch len(parts) {
+	case 0:
+		return Any{Description: description}, nil
+	case 1:
+		return withDescription(parts[0], description), nil
+	default:
+		return AllOf{Description: description, Schemas: parts}, nil
+	}
+}
+
+// parseType parses the keywords of the type into a Schema.
+// parseType returns nil if the type is neither given nor inferred.
+func (k keywords) parseType() (Schema, error) {
+	t, ok := k["type"]
+	if !ok {
+		switch {
+		case k["properties"] != nil:
+			t = "object"
+		case k["items"] != nil:
+			t = "array"
+		default:
+			return nil, nil
+		}
+	}
+
+	switch t := t.(type) {
+	case string:
+		return k.parseSingleType(t)
+	case []any:
+		if len(t) == 0 {
+			return nil, fmt.Errorf("type: must not be empty")
+		}
+		schemas := make([]Schema, len(t))
+		for i, t := range t {
+			name, ok := t.(string)
+			if !ok {
+				return nil, fmt.Errorf("type: must be a string or an array of strings")
+			}
+			schema, err := k.parseSingleType(name)
+			if err != nil {
+				return nil, err
+			}
+			schemas[i] = schema
+		}
+		if len(schemas) == 1 {
+			return schemas[0], nil
+		}
+		return AnyOf{Schemas: schemas}, nil
+	default:
+		return nil, fmt.Errorf("type: must be a string or an array of strings")
+	}
+}
+
+// parseSingleType parses the keywords of the type into a Schema.
+// The description is left to the caller.
+func (k keywords) parseSingleType(t string) (Schema, error) {
+	switch t {
+	case "string":
+		return k.parseString()
+	case "integer":
+		return k.parseInteger()
+	case "number":
+		return k.parseNumber()
+	case "boolean":
+		return Boolean{}, nil
+	case "null":
+		return Null{}, nil
+	case "array":
+		return k.parseArray()
+	case "object":
+		return k.parseObject()
+	default:
+		return nil, fmt.Errorf("type: unknown type %s", t)
+	}
+}
+
+func (k keywords) parseString() (Schema, error) {
+	var s String
+	var err error
+	if s.MinLength, err = k.int("minLength"); err != nil {
+		return nil, err
+	}
+	if s.MaxLength, err = k.int("maxLength"); err != nil {
+		return nil, err
+	}
+	if s.Pattern, err = k.string("pattern"); err != nil {
+		return nil, err
+	}
+	if s.Pattern != "" {
+		if _, err := compilePattern(s.Pattern); err != nil {
+			return nil, fmt.Errorf("pattern: %w", err)
+		}
+	}
+	if s.Format, err = k.string("format"); err != nil {
+		return nil, err
+	}
+	if values, ok := k["enum"]; ok {
+		values, ok := values.([]any)
+		if !ok {
+			return nil, fmt.Errorf("enum: must be an array")
+		}
+		// The values other than strings never match, so they are dropped
+		s.Enum = []string{}
+		for _, value := range values {
+			if str, ok := value.(string); ok {
+				s.Enum = append(s.Enum, str)
+			}
+		}
+	}
+	return s, nil
+}
+
+func (k keywords) parseInteger() (Schema, error) {
+	var s Integer
+	b, err := k.bounds()
+	if err != nil {
+		return nil, err
+	}
+
+	// A bound that isn't an integer is rounded to the inclusive bound of the integers within it
+	lower := func(name string, v float64, exclusive **int64) error {
+		if isInt64(v) && exclusive != nil {
+			n := int64(v)
+			*exclusive = &n
+			return nil
+		}
+		v = math.Ceil(v)
+		if !isInt64(v) {
+			return fmt.Errorf("%s: out of the range of integers", name)
+		}
+		if s.Minimum == nil || int64(v) > *s.Minimum {
+			n := int64(v)
+			s.Minimum = &n
+		}
+		return nil
+	}
+	upper := func(name string, v float64, exclusive **int64) error {
+		if isInt64(v) && exclusive != nil {
+			n := int64(v)
+			*exclusive = &n
+			return nil
+		}
+		v = math.Floor(v)
+		if !isInt64(v) {
+			return fmt.Errorf("%s: out of the range of integers", name)
+		}
+		if s.Maximum == nil || int64(v) < *s.Maximum {
+			n := int64(v)
+			s.Maximum = &n
+		}
+		return nil
+	}
+	if b.minimum != nil {
+		if err := lower("minimum", *b.minimum, nil); err != nil {
+			return nil, err
+		}
+	}
+	if b.exclusiveMinimum != nil {
+		if err := lower("exclusiveMinimum", *b.exclusiveMinimum, &s.ExclusiveMinimum); err != nil {
+			return nil, err
+		}
+	}
+	if b.maximum != nil {
+		if err := upper("maximum", *b.maximum, nil); err != nil {
+			return nil, err
+		}
+	}
+	if b.exclusiveMaximum != nil {
+		if err := upper("exclusiveMaximum", *b.exclusiveMaximum, &s.ExclusiveMaximum); err != nil {
+			return nil, err
+		}
+	}
+	if values, ok := k["enum"]; ok {
+		values, ok := values.([]any)
+		if !ok {
+			return nil, fmt.Errorf("enum: must be an array")
+		}
+		// The values other than integers never match, so they are dropped
+		s.Enum = []int64{}
+		for _, value := range values {
+			if f, ok := value.(float64); ok && isInt64(f) {
+				s.Enum = append(s.Enum, int64(f))
+			}
+		}
+	}
+	return s, nil
+}
+
+func (k keywords) parseNumber() (Schema, error) {
+	var s Number
+	b, err := k.bounds()
+	if err != nil {
+		return nil, err
+	}
+	s.Minimum, s.Maximum, s.ExclusiveMinimum, s.ExclusiveMaximum = b.minimum, b.maximum, b.exclusiveMinimum, b.exclusiveMaximum
+	if values, ok := k["enum"]; ok {
+		values, ok := values.([]any)
+		if !ok {
+			return nil, fmt.Errorf("enum: must be an array")
+		}
+		// The values other than numbers never match, so they are dropped
+		s.Enum = []float64{}
+		for _, value := range values {
+			if f, ok := value.(float64); ok {
+				s.Enum = append(s.Enum, f)
+			}
+		}
+	}
+	return s, nil
+}
+
+// numericBounds are the bounds of a number.
+type numericBounds struct {
+	minimum, maximum, exclusiveMinimum, exclusiveMaximum *float64
+}
+
+// bounds returns the bounds of a number.
+// The boolean exclusiveMinimum and exclusiveMaximum of draft-04, which make minimum and maximum exclusive,
+// are converted into the numbers of the later drafts.
+func (k keywords) bounds() (numericBounds, error) {
+	var b numericBounds
+	for name, dst := range map[string]**float64{
+		"minimum":          &b.minimum,
+		"maximum":          &b.maximum,
+		"exclusiveMinimum": &b.exclusiveMinimum,
+		"exclusiveMaximum": &b.exclusiveMaximum,
+	} {
+		if _, isBool := k[name].(bool); isBool {
+			continue
+		}
+		v, ok, err := k.number(name)
+		if err != nil {
+			return numericBounds{}, err
+		}
+		if ok {
+			*dst = &v
+		}
+	}
+
+	if exclusive, _ := k["exclusiveMinimum"].(bool); exclusive && b.minimum != nil {
+		b.minimum, b.exclusiveMinimum = nil, b.minimum
+	}
+	if exclusive, _ := k["exclusiveMaximum"].(bool); exclusive && b.maximum != nil {
+		b.maximum, b.exclusiveMaximum = nil, b.maximum
+	}
+	return b, nil
+}
+
+func (k keywords) parseArray() (Schema, error) {
+	var s Array
+	var err error
+	if s.MinItems, err = k.int("minItems"); err != nil {
+		return nil, err
+	}
+	if s.MaxItems, err = k.int("maxItems"); err != nil {
+		return nil, err
+	}
+	// The array form of items for tuple validation isn't supported, and any item is accepted
+	if _, isTuple := k["items"].([]any); isTuple {
+		s.Items = Any{}
+		return s, nil
+	}
+	items, ok, err := k.schema("items")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		items = Any{}
+	}
+	s.Items = items
+	return s, nil
+}
+
+func (k keywords) parseObject() (Schema, error) {
+	var o Object
+	var err error
+	if o.Properties, err = k.schemaMap("properties"); err != nil {
+		return nil, err
+	}
+	if o.PatternProperties, err = k.schemaMap("patternProperties"); err != nil {
+		return nil, err
+	}
+	for pattern := range o.PatternProperties {
+		if _, err := compilePattern(pattern); err != nil {
+			return nil, fmt.Errorf("patternProperties: %w", err)
+		}
+	}
+	if o.PropertyNames, _, err = k.schema("propertyNames"); err != nil {
+		return nil, err
+	}
+	if o.MinProperties, err = k.int("minProperties"); err != nil {
+		return nil, err
+	}
+	if o.MaxProperties, err = k.int("maxProperties"); err != nil {
+		return nil, err
+	}
+	if o.DependentRequired, err = k.stringsMap("dependentRequired"); err != nil {
+		return nil, err
+	}
+	if o.Defs, err = k.defs(); err != nil {
+		return nil, err
+	}
+	if o.Required, err = k.strings("required"); err != nil {
+		return nil, err
+	}
+	for _, r := range o.Required {
+		if _, ok := o.Properties[r]; !ok {
+			return nil, fmt.Errorf("required: property %s not found", r)
+		}
+	}
+
+	additional, hasAdditional, err := k.schema("additionalProperties")
+	if err != nil {
+		return nil, err
+	}
+	rejectsAdditional := hasAdditional && additional == Schema(Not{Schema: Any{}})
+
+	// An object constrained only by an additionalProperties schema is a map.
+	// The boolean additionalProperties, as marshaled by Object, keeps it an object.
+	if _, isBool := k["additionalProperties"].(bool); reflect.ValueOf(o).IsZero() && hasAdditional && !isBool {
+		return Map{AdditionalProperties: additional}, nil
+	}
+
+	// JSON schema accepts additional properties by default
+	switch {
+	case !hasAdditional:
+		o.AdditionalProperties = Any{}
+	case !rejectsAdditional:
+		o.AdditionalProperties = additional
+	}
+	return o, nil
+}
+
+// definitionsPrefix is the prefix of the references to the definitions in draft-07, which are parsed as $defs.
+const definitionsPrefix = "#/definitions/"
+
+// defs returns the definitions of "$defs" and "definitions", or nil if neither is given.
+// A definition in "$defs" takes precedence over the one of the same name in "definitions".
+func (k keywords) defs() (map[string]Schema, error) {
+	defs, err := k.schemaMap("definitions")
+	if err != nil {
+		return nil, err
+	}
+	newDefs, err := k.schemaMap("$defs")
+	if err != nil {
+		return nil, err
+	}
+	if defs == nil {
+		return newDefs, nil
+	}
+	maps.Copy(defs, newDefs)
+	return defs, nil
+}
+
+// string returns the string keyword, or "" if it's not given.
+func (k keywords) string(name string) (string, error) {
+	v, ok := k[name]
+	if !ok {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%s: must be a string", name)
+	}
+	return s, nil
+}
+
+// strings returns the array of strings keyword, or nil if it's not given.
+func (k keywords) strings(name string) ([]string, error) {
+	v, ok := k[name]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	values, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be an array of strings", name)
+	}
+	strs := make([]string, len(values))
+	for i, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: must be an array of strings", name)
+		}
+		strs[i] = s
+	}
+	return strs, nil
+}
+
+// stringsMap returns the object of arrays of strings keyword, or nil if it's not given.
+func (k keywords) stringsMap(name string) (map[string][]string, error) {
+	v, ok := k[name]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	values, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be an object", name)
+	}
+	m := make(map[string][]string, len(values))
+	for key, value := range values {
+		strs, err := keywords{key: value}.strings(key)
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s", name, err)
+		}
+		m[key] = strs
+	}
+	return m, nil
+}
+
+// number returns the number keyword, and whether it's given.
+func (k keywords) number(name string) (float64, bool, error) {
+	v, ok := k[name]
+	if !ok {
+		return 0, false, nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return 0, false, fmt.Errorf("%s: must be a number", name)
+	}
+	return f, true, nil
+}
+
+// int returns the non-negative integer keyword, or 0 if it's not given.
+func (k keywords) int(name string) (int, error) {
+	f, ok, err := k.number(name)
+	if err != nil || !ok {
+		return 0, err
+	}
+	if f < 0 || f > math.MaxInt32 || f != math.Trunc(f) {
+		return 0, fmt.Errorf("%s: must be a non-negative integer", name)
+	}
+	return int(f), nil
+}
+
+// schema returns the schema keyword, and whether it's given.
+func (k keywords) schema(name string) (Schema, bool, error) {
+	v, ok := k[name]
+	if !ok || v == nil {
+		return nil, false, nil
+	}
+	s, err := parseSchema(v)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", name, err)
+	}
+	return s, true, nil
+}
+
+// schemas returns the array of schemas keyword.
+func (k keywords) schemas(name string) ([]Schema, error) {
+	values, ok := k[name].([]any)
+	if !ok || len(values) == 0 {
+		return nil, fmt.Errorf("%s: must be a non-empty array", name)
+	}
+	schemas := make([]Schema, len(values))
+	for i, value := range values {
+		s, err := parseSchema(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s/%d: %w", name, i, err)
+		}
+		schemas[i] = s
+	}
+	return schemas, nil
+}
+
+// schemaMap returns the object of schemas keyword, or nil if it's not given.
+func (k keywords) schemaMap(name string) (map[string]Schema, error) {
+	v, ok := k[name]
+	if !ok || v == nil {
+		return nil, nil
+	}
+	values, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be an object", name)
+	}
+	schemas := make(map[string]Schema, len(values))
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		s, err := parseSchema(values[key])
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", name, escapePointerToken(key), err)
+		}
+		schemas[key] = s
+	}
+	return schemas, nil
+}
+
+// withDescription returns the schema with the description set.
+func withDescription(s Schema, description string) Schema {
+	switch s := s.(type) {
+	case String:
+		s.Description = description
+		return s
+	case Integer:
+		s.Description = description
+		return s
+	case Number:
+		s.Description = description
+		return s
+	case Boolean:
+		s.Description = description
+		return s
+	case Null:
+		s.Description = description
+		return s
+	case Array:
+		s.Description = description
+		return s
+	case Object:
+		s.Description = description
+		return s
+	case Map:
+		s.Description = description
+		return s
+	case Const:
+		s.Description = description
+		return s
+	case Ref:
+		s.Description = description
+		return s
+	case OneOf:
+		s.Description = description
+		return s
+	case AnyOf:
+		s.Description = description
+		return s
+	case AllOf:
+		s.Description = description
+		return s
+	case Not:
+		s.Description = description
+		return s
+	case Any:
+		s.Description = description
+		return s
+	default:
+		return s
+	}
+}
+
+// isInt64 reports whether f is an integer representable as int64.
+// hasEnum reports whether the schema has its own Enum field.
+func hasEnum(s Schema) bool {
+	switch s.(type) {
+	case String, Integer, Number:
+		return true
+	default:
+		return false
+	}
+}
+
+func isInt64(f float64) bool {
+	return f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64
+}
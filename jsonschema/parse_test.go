@@ -0,0 +1,388 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Schema
+		wantErr bool
+	}{
+		{
+			name:  "string",
+			input: `{"type":"string","description":"a name","minLength":1,"maxLength":10,"pattern":"^[a-z]+$","format":"hostname","enum":["a","b",1]}`,
+			want:  String{Description: "a name", MinLength: 1, MaxLength: 10, Pattern: "^[a-z]+$", Format: "hostname", Enum: []string{"a", "b"}},
+		},
+		{
+			name:  "integer",
+			input: `{"type":"integer","minimum":1,"exclusiveMaximum":10}`,
+			want:  Integer{Minimum: ptr(int64(1)), ExclusiveMaximum: ptr(int64(10))},
+		},
+		{
+			name:  "integer with a fractional minimum",
+			input: `{"type":"integer","minimum":1.5}`,
+			want:  Integer{Minimum: ptr(int64(2))},
+		},
+		{
+			name:  "integer with fractional bounds",
+			input: `{"type":"integer","minimum":0.5,"exclusiveMinimum":2.5,"maximum":9.5,"exclusiveMaximum":7.5}`,
+			want:  Integer{Minimum: ptr(int64(3)), Maximum: ptr(int64(7))},
+		},
+		{
+			name:    "integer with a bound out of range",
+			input:   `{"type":"integer","minimum":1e300}`,
+			wantErr: true,
+		},
+		{
+			name:  "draft-04 exclusive bounds",
+			input: `{"type":"number","minimum":0,"exclusiveMinimum":true,"maximum":1,"exclusiveMaximum":false}`,
+			want:  Number{ExclusiveMinimum: ptr(0.0), Maximum: ptr(1.0)},
+		},
+		{
+			name:  "draft-04 exclusive bounds of an integer",
+			input: `{"type":"integer","minimum":0,"exclusiveMinimum":true,"maximum":10,"exclusiveMaximum":true}`,
+			want:  Integer{ExclusiveMinimum: ptr(int64(0)), ExclusiveMaximum: ptr(int64(10))},
+		},
+		{
+			name:  "enum of boolean",
+			input: `{"type":"boolean","enum":[true]}`,
+			want:  AllOf{Schemas: []Schema{Boolean{}, AnyOf{Schemas: []Schema{Const{Value: true}}}}},
+		},
+		{
+			name:  "enum of object",
+			input: `{"type":"object","enum":[{"a":1}]}`,
+			want: AllOf{Schemas: []Schema{
+				Object{AdditionalProperties: Any{}},
+				AnyOf{Schemas: []Schema{Const{Value: map[string]any{"a": 1.0}}}},
+			}},
+		},
+		{
+			name:  "enum with a type array",
+			input: `{"type":["string","null"],"enum":["a",null]}`,
+			want: AllOf{Schemas: []Schema{
+				AnyOf{Schemas: []Schema{String{Enum: []string{"a"}}, Null{}}},
+				AnyOf{Schemas: []Schema{Const{Value: "a"}, Const{Value: nil}}},
+			}},
+		},
+		{
+			name:  "enum without matching values",
+			input: `{"type":"string","enum":[1]}`,
+			want:  String{Enum: []string{}},
+		},
+		{
+			name:  "number",
+			input: `{"type":"number","maximum":1.5,"enum":[0.5,1]}`,
+			want:  Number{Maximum: ptr(1.5), Enum: []float64{0.5, 1}},
+		},
+		{
+			name:  "type array",
+			input: `{"type":["string","null"],"description":"optional","maxLength":3}`,
+			want:  AnyOf{Description: "optional", Schemas: []Schema{String{MaxLength: 3}, Null{}}},
+		},
+		{
+			name:  "object",
+			input: `{"type":"object","properties":{"a":{"type":"string"},"b":{"type":"array","items":{"type":"boolean"}}},"required":["a"]}`,
+			want: Object{
+				Properties: map[string]Schema{
+					"a": String{},
+					"b": Array{Items: Boolean{}},
+				},
+				Required:             []string{"a"},
+				AdditionalProperties: Any{},
+			},
+		},
+		{
+			name:  "object inferred from properties",
+			input: `{"properties":{"a":{}}}`,
+			want:  Object{Properties: map[string]Schema{"a": Any{}}, AdditionalProperties: Any{}},
+		},
+		{
+			name:  "object without properties",
+			input: `{"type":"object"}`,
+			want:  Object{AdditionalProperties: Any{}},
+		},
+		{
+			name:  "object accepting any additional property",
+			input: `{"type":"object","additionalProperties":true}`,
+			want:  Object{AdditionalProperties: Any{}},
+		},
+		{
+			name:  "map of any value",
+			input: `{"type":"object","additionalProperties":{}}`,
+			want:  Map{AdditionalProperties: Any{}},
+		},
+		{
+			name:  "closed object",
+			input: `{"type":"object","properties":{"a":{"type":"string"}},"additionalProperties":false}`,
+			want:  Object{Properties: map[string]Schema{"a": String{}}},
+		},
+		{
+			name:  "object keywords",
+			input: `{"type":"object","properties":{"a":{}},"additionalProperties":{"type":"integer"},"patternProperties":{"^x-":{"type":"string"}},"propertyNames":{"maxLength":8},"minProperties":1,"maxProperties":4,"dependentRequired":{"a":["b"]}}`,
+			want: Object{
+				Properties:           map[string]Schema{"a": Any{}},
+				AdditionalProperties: Integer{},
+				PatternProperties:    map[string]Schema{"^x-": String{}},
+				PropertyNames:        Any{},
+				MinProperties:        1,
+				MaxProperties:        4,
+				DependentRequired:    map[string][]string{"a": {"b"}},
+			},
+		},
+		{
+			name:  "map",
+			input: `{"type":"object","additionalProperties":{"type":"integer"}}`,
+			want:  Map{AdditionalProperties: Integer{}},
+		},
+		{
+			name:  "tuple items",
+			input: `{"type":"array","items":[{"type":"string"},{"type":"integer"}],"maxItems":2}`,
+			want:  Array{MaxItems: 2, Items: Any{}},
+		},
+		{
+			name:  "array without items",
+			input: `{"type":"array","minItems":1}`,
+			want:  Array{MinItems: 1, Items: Any{}},
+		},
+		{
+			name:  "definitions and references",
+			input: `{"type":"object","properties":{"root":{"$ref":"#/$defs/node"}},"$defs":{"node":{"type":"object","properties":{"next":{"$ref":"#/$defs/node"}}}}}`,
+			want: Object{
+				Properties:           map[string]Schema{"root": RefTo("node")},
+				AdditionalProperties: Any{},
+				Defs: map[string]Schema{
+					"node": Object{Properties: map[string]Schema{"next": RefTo("node")}, AdditionalProperties: Any{}},
+				},
+			},
+		},
+		{
+			name:  "draft-07 definitions",
+			input: `{"type":"object","properties":{"a":{"$ref":"#/definitions/n"}},"definitions":{"n":{"type":"integer"}},"$defs":{"s":{"type":"string"}}}`,
+			want: Object{
+				Properties:           map[string]Schema{"a": RefTo("n")},
+				AdditionalProperties: Any{},
+				Defs:                 map[string]Schema{"n": Integer{}, "s": String{}},
+			},
+		},
+		{
+			name:  "root reference to a definition",
+			input: `{"$schema":"http://json-schema.org/draft-07/schema#","$ref":"#/definitions/args","description":"the arguments","definitions":{"args":{"type":"object","properties":{"name":{"$ref":"#/definitions/name"}}},"name":{"type":"string"}}}`,
+			want: Object{
+				Description:          "the arguments",
+				Properties:           map[string]Schema{"name": RefTo("name")},
+				AdditionalProperties: Any{},
+				Defs: map[string]Schema{
+					"args": Object{Properties: map[string]Schema{"name": RefTo("name")}, AdditionalProperties: Any{}},
+					"name": String{},
+				},
+			},
+		},
+		{
+			name:    "unresolved reference",
+			input:   `{"type":"object","properties":{"a":{"$ref":"#/$defs/missing"}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "external reference",
+			input:   `{"$ref":"https://example.com/schema.json"}`,
+			wantErr: true,
+		},
+		{
+			name:  "reference to a definition that isn't an object",
+			input: `{"$ref":"#/definitions/name","definitions":{"name":{"type":"string"}}}`,
+			want:  AllOf{Schemas: []Schema{RefTo("name")}, Defs: map[string]Schema{"name": String{}}},
+		},
+		{
+			name:  "definitions with allOf at the root",
+			input: `{"type":"object","properties":{"a":{"$ref":"#/$defs/A"}},"$defs":{"A":{"type":"string"}},"allOf":[{"required":["a"]}]}`,
+			want: AllOf{
+				Schemas: []Schema{
+					Object{Properties: map[string]Schema{"a": RefTo("A")}, AdditionalProperties: Any{}},
+					AllOf{Schemas: []Schema{Any{}}},
+				},
+				Defs: map[string]Schema{"A": String{}},
+			},
+		},
+		{
+			name:  "definitions with a type array at the root",
+			input: `{"type":["object","null"],"properties":{"a":{"$ref":"#/$defs/A"}},"$defs":{"A":{"type":"string"}}}`,
+			want: AllOf{
+				Schemas: []Schema{AnyOf{Schemas: []Schema{
+					Object{Properties: map[string]Schema{"a": RefTo("A")}, AdditionalProperties: Any{}},
+					Null{},
+				}}},
+				Defs: map[string]Schema{"A": String{}},
+			},
+		},
+		{
+			name:  "definitions with oneOf at the root",
+			input: `{"oneOf":[{"$ref":"#/$defs/A"},{"type":"null"}],"$defs":{"A":{"type":"string"}}}`,
+			want: AllOf{
+				Schemas: []Schema{OneOf{Schemas: []Schema{RefTo("A"), Null{}}}},
+				Defs:    map[string]Schema{"A": String{}},
+			},
+		},
+		{
+			name:    "unresolved reference in the definitions of a root that isn't an object",
+			input:   `{"allOf":[{"$ref":"#/$defs/A"}],"$defs":{"A":{"$ref":"#/$defs/missing"}}}`,
+			wantErr: true,
+		},
+		{
+			name:  "combinators",
+			input: `{"oneOf":[{"type":"string"},{"anyOf":[{"type":"integer"},{"not":{"type":"null"}}]}]}`,
+			want: OneOf{Schemas: []Schema{
+				String{},
+				AnyOf{Schemas: []Schema{Integer{}, Not{Schema: Null{}}}},
+			}},
+		},
+		{
+			name:  "type with combinator",
+			input: `{"type":"string","description":"an id","allOf":[{"minLength":1}]}`,
+			want:  AllOf{Description: "an id", Schemas: []Schema{String{}, AllOf{Schemas: []Schema{Any{}}}}},
+		},
+		{
+			name:  "enum without type",
+			input: `{"enum":["a",1,null]}`,
+			want:  AnyOf{Schemas: []Schema{Const{Value: "a"}, Const{Value: 1.0}, Const{Value: nil}}},
+		},
+		{
+			name:  "const",
+			input: `{"const":{"a":1},"description":"fixed"}`,
+			want:  Const{Description: "fixed", Value: map[string]any{"a": 1.0}},
+		},
+		{
+			name:  "boolean schemas",
+			input: `{"type":"object","properties":{"a":true,"b":false}}`,
+			want:  Object{Properties: map[string]Schema{"a": Any{}, "b": Not{Schema: Any{}}}, AdditionalProperties: Any{}},
+		},
+		{
+			name:    "unknown type",
+			input:   `{"type":"date"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid nested schema",
+			input:   `{"type":"object","properties":{"a":{"type":1}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "missing required property",
+			input:   `{"type":"object","properties":{},"required":["a"]}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid pattern",
+			input:   `{"type":"string","pattern":"(?=a)"}`,
+			wantErr: true,
+		},
+		{
+			name:    "empty oneOf",
+			input:   `{"oneOf":[]}`,
+			wantErr: true,
+		},
+		{
+			name:    "not a schema",
+			input:   `"string"`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	schemas := []Schema{
+		String{Description: "a string", MinLength: 1, MaxLength: 5, Pattern: "^a", Format: "email", Enum: []string{"a@example.com"}},
+		Integer{Minimum: ptr(int64(0)), Maximum: ptr(int64(10)), Enum: []int64{1, 2}},
+		Number{ExclusiveMinimum: ptr(0.5)},
+		Boolean{Description: "a flag"},
+		Null{},
+		Array{MinItems: 1, MaxItems: 2, Items: String{}},
+		Map{Description: "labels", AdditionalProperties: String{}},
+		Map{AdditionalProperties: Any{}},
+		Object{},
+		Object{AdditionalProperties: Any{}},
+		Object{
+			Properties:           map[string]Schema{"a": String{}},
+			AdditionalProperties: Any{},
+			PatternProperties:    map[string]Schema{"^x-": Integer{}},
+			PropertyNames:        String{MaxLength: 8},
+			MinProperties:        1,
+			MaxProperties:        2,
+			DependentRequired:    map[string][]string{"a": {"b", "c"}},
+		},
+		Object{MinProperties: 1, AdditionalProperties: Boolean{}},
+		Object{
+			Description: "a tree",
+			Properties: map[string]Schema{
+				"root": RefTo("node"),
+				"tag":  AnyOf{Schemas: []Schema{String{}, Null{}}},
+			},
+			Required: []string{"root"},
+			Defs: map[string]Schema{
+				"node": Object{
+					Properties: map[string]Schema{
+						"value":    OneOf{Schemas: []Schema{String{}, Integer{}}},
+						"children": Array{Items: RefTo("node")},
+						"parent":   Ref{Ref: "#", Description: "the root"},
+					},
+				},
+			},
+		},
+		Const{Description: "a const", Value: "fixed"},
+		AllOf{Description: "both", Schemas: []Schema{Not{Schema: Null{}}, Any{}}},
+		AllOf{Schemas: []Schema{AnyOf{Schemas: []Schema{RefTo("a"), Null{}}}}, Defs: map[string]Schema{"a": String{}}},
+		String{Enum: []string{}},
+		Integer{Enum: []int64{}},
+		Number{Enum: []float64{}},
+		Object{AdditionalProperties: Integer{}, Defs: map[string]Schema{"a": String{}}},
+	}
+
+	for _, s := range schemas {
+		data, err := json.Marshal(s)
+		if err != nil {
+			t.Fatalf("Marshal(%#v) error = %v", s, err)
+		}
+		t.Run(string(data), func(t *testing.T) {
+			got, err := Parse(data)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, s) {
+				t.Errorf("Parse() = %#v, want %#v", got, s)
+			}
+		})
+	}
+}
+
+func TestParse_RootDefinitions(t *testing.T) {
+	schema, err := Parse([]byte(`{"type":"object","properties":{"a":{"$ref":"#/$defs/A"}},"$defs":{"A":{"type":"string"}},"allOf":[{"type":"object"}]}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if err := schema.Validate(json.RawMessage(`{"a":"x"}`)); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := schema.Validate(json.RawMessage(`{"a":1}`)); err == nil {
+		t.Errorf("Validate() error = nil, want error")
+	}
+}
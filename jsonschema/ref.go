@@ -0,0 +1,102 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// defsPrefix is the prefix of the references to the definitions of the root schema.
+const defsPrefix = "#/$defs/"
+
+// maxRefDepth is the maximum number of the references followed while validating a value.
+// It stops the validation of a reference that refers to itself without consuming the value,
+// e.g. a definition that is a reference to itself.
+const maxRefDepth = 1000
+
+// Ref is a JSON schema $ref, which refers to another schema of the same document.
+//
+// Ref is either "#" for the root schema, or "#/$defs/<name>" for a definition of the root Object or AllOf.
+// The name is escaped as a JSON Pointer token, and other references are not supported.
+type Ref struct {
+	Description string `json:"description,omitempty"`
+	Ref         string `json:"$ref"`
+}
+
+// RefTo returns a Ref to the definition of the name.
+func RefTo(name string) Ref {
+	return Ref{Ref: defsPrefix + escapePointerToken(name)}
+}
+
+// Validate validates the value against the JSON schema.
+// Validate always fails, as a Ref at the root of a document has nothing to refer to.
+func (s Ref) Validate(v json.RawMessage) error {
+	var m any
+	if err := json.Unmarshal(v, &m); err != nil {
+		return fmt.Errorf("unmarshal: %w", err)
+	}
+
+	return validateValue(s, m)
+}
+
+// validate validates the value against the referenced schema.
+func (s Ref) validate(c *validationContext, location string, v any) []Violation {
+	target, err := c.resolve(s.Ref)
+	if err != nil {
+		return []Violation{newViolation(location, "$ref", "%s", err)}
+	}
+
+	if c.depth >= maxRefDepth {
+		return []Violation{newViolation(location, "$ref", "too many nested references: %s", s.Ref)}
+	}
+	c.depth++
+	defer func() { c.depth-- }()
+
+	return target.validate(c, location, v)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s Ref) MarshalJSON() ([]byte, error) {
+	if s.Ref != "#" && !strings.HasPrefix(s.Ref, defsPrefix) {
+		return nil, fmt.Errorf("unsupported reference: %s", s.Ref)
+	}
+
+	type refSchema Ref
+
+	return json.Marshal(struct {
+		refSchema
+	}{
+		refSchema: refSchema(s),
+	})
+}
+
+// resolve returns the schema referenced by ref.
+func (c *validationContext) resolve(ref string) (Schema, error) {
+	if ref == "#" {
+		if _, ok := c.root.(Ref); ok {
+			return nil, fmt.Errorf("reference %s refers to itself", ref)
+		}
+		return c.root, nil
+	}
+
+	token, ok := strings.CutPrefix(ref, defsPrefix)
+	if !ok {
+		return nil, fmt.Errorf("unsupported reference: %s", ref)
+	}
+
+	target, ok := c.defs[unescapePointerToken(token)]
+	if !ok {
+		return nil, fmt.Errorf("unresolved reference: %s", ref)
+	}
+	return target, nil
+}
+
+// escapePointerToken escapes a reference token of a JSON Pointer as defined in RFC 6901.
+func escapePointerToken(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+// unescapePointerToken unescapes a reference token of a JSON Pointer as defined in RFC 6901.
+func unescapePointerToken(s string) string {
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(s)
+}
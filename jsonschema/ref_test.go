@@ -0,0 +1,138 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRef_Validate(t *testing.T) {
+	tree := Object{
+		Properties: map[string]Schema{
+			"root": RefTo("node"),
+		},
+		Defs: map[string]Schema{
+			"node": Object{
+				Properties: map[string]Schema{
+					"value":    String{},
+					"children": Array{Items: RefTo("node")},
+				},
+				Required: []string{"value"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		schema  Schema
+		input   json.RawMessage
+		wantErr bool
+	}{
+		{
+			name:   "recursive definition",
+			schema: tree,
+			input:  json.RawMessage(`{"root": {"value": "a", "children": [{"value": "b", "children": [{"value": "c"}]}]}}`),
+		},
+		{
+			name:    "invalid value in recursive definition",
+			schema:  tree,
+			input:   json.RawMessage(`{"root": {"value": "a", "children": [{"children": []}]}}`),
+			wantErr: true,
+		},
+		{
+			name: "reference to root",
+			schema: Object{
+				Properties: map[string]Schema{
+					"next": Ref{Ref: "#"},
+				},
+			},
+			input: json.RawMessage(`{"next": {"next": {}}}`),
+		},
+		{
+			name: "escaped name",
+			schema: Object{
+				Properties: map[string]Schema{"a": RefTo("a/b~c")},
+				Defs:       map[string]Schema{"a/b~c": Integer{}},
+			},
+			input: json.RawMessage(`{"a": 1}`),
+		},
+		{
+			name: "unresolved reference",
+			schema: Object{
+				Properties: map[string]Schema{"a": RefTo("missing")},
+			},
+			input:   json.RawMessage(`{"a": 1}`),
+			wantErr: true,
+		},
+		{
+			name: "definition referring to itself",
+			schema: Object{
+				Properties: map[string]Schema{"a": RefTo("loop")},
+				Defs:       map[string]Schema{"loop": RefTo("loop")},
+			},
+			input:   json.RawMessage(`{"a": 1}`),
+			wantErr: true,
+		},
+		{
+			name:    "ref at root",
+			schema:  Ref{Ref: "#"},
+			input:   json.RawMessage(`1`),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schema.Validate(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRef_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  Schema
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "definition",
+			schema: Ref{Ref: "#/$defs/node", Description: "a node"},
+			want:   `{"$ref":"#/$defs/node","description":"a node"}`,
+		},
+		{
+			name:   "root",
+			schema: Ref{Ref: "#"},
+			want:   `{"$ref":"#"}`,
+		},
+		{
+			name: "object with definitions",
+			schema: Object{
+				Properties: map[string]Schema{"root": RefTo("node")},
+				Defs:       map[string]Schema{"node": String{}},
+			},
+			want: `{"type":"object","additionalProperties":false,"properties":{"root":{"$ref":"#/$defs/node"}},"$defs":{"node":{"type":"string"}}}`,
+		},
+		{
+			name:    "external reference",
+			schema:  Ref{Ref: "https://example.com/schema.json"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.schema.MarshalJSON()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			assertJSONEqual(t, tt.want, string(got))
+		})
+	}
+}
@@ -1,17 +1,66 @@
 package jsonschema
 
 import (
+	"encoding"
+	"encoding/json"
 	"fmt"
+	"math"
+	"net/netip"
+	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // SchemaTag represents the struct tag for JSON schema configuration
 type SchemaTag struct {
 	Description string
 	Required    bool
+	// Enum is the list of the allowed values given as "enum=a|b|c"
+	Enum []string
+	// Inline merges the properties of a struct field into the parent object,
+	// or makes the values of a map field the additional properties of the parent object.
+	// encoding/json doesn't flatten the inline fields, so decode the values with Unmarshal instead of json.Unmarshal.
+	Inline bool
 }
 
+// Enumer is implemented by types that have a fixed set of values.
+// generateSchema uses JSONSchemaEnum to set the enum of the schema generated for the type.
+type Enumer interface {
+	JSONSchemaEnum() []any
+}
+
+var enumerType = reflect.TypeFor[Enumer]()
+
+// Schemer is implemented by types that supply their own schema.
+// generateSchema uses JSONSchema instead of generating the schema from the kind of the type.
+type Schemer interface {
+	JSONSchema() Schema
+}
+
+var (
+	schemerType       = reflect.TypeFor[Schemer]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// builtinSchemas maps the types of the standard library to their schemas, following their encoding by encoding/json.
+// url.URL is an exception: encoding/json encodes it as a struct, but its schema is a URI string, which Unmarshal decodes.
+var builtinSchemas = map[reflect.Type]Schema{
+	reflect.TypeFor[time.Time]():       String{Format: "date-time"},
+	reflect.TypeFor[time.Duration]():   Integer{},
+	reflect.TypeFor[json.RawMessage](): Any{},
+	reflect.TypeFor[url.URL]():         String{Format: "uri"},
+	reflect.TypeFor[netip.Addr]():      AnyOf{Schemas: []Schema{String{Format: "ipv4"}, String{Format: "ipv6"}}},
+}
+
+// base64Pattern is the pattern of the strings encoded by encoding/base64.StdEncoding, as []byte is encoded by encoding/json.
+const base64Pattern = `^[A-Za-z0-9+/]*={0,2}$`
+
+// integerKeyPattern is the pattern of the keys of a map with integer keys, which are encoded as decimal strings.
+const integerKeyPattern = `^-?[0-9]+$`
+
 // ParseSchemaTag parses the jsonschema tag and returns SchemaTag
 func ParseSchemaTag(tag reflect.StructTag) SchemaTag {
 	t := tag.Get("jsonschema")
@@ -26,14 +75,34 @@ func ParseSchemaTag(tag reflect.StructTag) SchemaTag {
 			st.Required = true
 			continue
 		}
+		if p == "inline" {
+			st.Inline = true
+			continue
+		}
 		if strings.HasPrefix(p, "description=") {
 			st.Description = strings.TrimPrefix(p, "description=")
 		}
+		if strings.HasPrefix(p, "enum=") {
+			st.Enum = strings.Split(strings.TrimPrefix(p, "enum="), "|")
+		}
 	}
 	return st
 }
 
 // FromStruct generates a JSON schema Object from a struct type
+//
+// The properties of the Object follow the encoding of the struct by encoding/json:
+// the fields tagged with json:"-" are skipped, the fields of embedded structs are promoted,
+// and the fields with the ",string" option are strings.
+// A type implementing Schemer supplies its own schema, and the types such as time.Time, json.RawMessage and []byte
+// have the schemas of their JSON encodings. Interfaces accept any value.
+//
+// Named struct types used by more than one field, or containing themselves, are emitted once
+// in the definitions of the Object and referred by Ref. The struct type itself is referred by "#".
+//
+// A struct field tagged with "inline" is merged into the Object as an embedded struct.
+// A map field tagged with "inline" gives the schema of the additional properties of the Object.
+// The values of such a struct are decoded by Unmarshal.
 func FromStruct(v any) (Object, error) {
 	return fromStructType(reflect.TypeOf(v))
 }
@@ -50,42 +119,187 @@ func fromStructType(t reflect.Type) (Object, error) {
 		return Object{}, fmt.Errorf("input must be a struct or pointer to struct")
 	}
 
-	obj := Object{
-		Properties: make(map[string]Schema),
+	g := &generator{
+		root:      t,
+		uses:      make(map[reflect.Type]int),
+		recursive: make(map[reflect.Type]bool),
+		names:     make(map[reflect.Type]string),
+		defs:      make(map[string]Schema),
 	}
+	g.countStruct(t, map[reflect.Type]bool{t: true})
 
-	var required []string
-	for i := range t.NumField() {
-		field := t.Field(i)
+	obj, err := g.object(t)
+	if err != nil {
+		return Object{}, err
+	}
+	if len(g.defs) > 0 {
+		obj.Defs = g.defs
+	}
+	return obj, nil
+}
 
-		// Skip unexported fields
-		if !field.IsExported() {
-			continue
+// generator generates the schemas for the types reachable from a root struct type.
+type generator struct {
+	root reflect.Type
+	// uses counts the fields referring to each named struct type
+	uses map[reflect.Type]int
+	// recursive is the set of the named struct types that contain themselves
+	recursive map[reflect.Type]bool
+	// names maps the struct types emitted as definitions to their names
+	names map[reflect.Type]string
+	defs  map[string]Schema
+}
+
+// count counts the uses of the named struct types reachable from t.
+// stack is the set of the struct types being counted, to detect recursive types.
+func (g *generator) count(t reflect.Type, stack map[reflect.Type]bool) {
+	if _, ok := customSchema(t); ok {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		g.count(t.Elem(), stack)
+	case reflect.Struct:
+		if t.Name() == "" {
+			g.countStruct(t, stack)
+			return
+		}
+		g.uses[t]++
+		if stack[t] {
+			g.recursive[t] = true
+			return
 		}
+		// The fields are counted only once, as the struct is emitted as a definition if it's used twice
+		if g.uses[t] > 1 {
+			return
+		}
+		stack[t] = true
+		g.countStruct(t, stack)
+		delete(stack, t)
+	}
+}
 
-		// Get JSON field name
-		jsonTag := field.Tag.Get("json")
-		name := field.Name
-		if jsonTag != "" {
-			parts := strings.Split(jsonTag, ",")
-			if parts[0] != "" && parts[0] != "-" {
-				name = parts[0]
+// countStruct counts the uses of the named struct types in the fields of t.
+// The errors are left to the generation of the schema.
+func (g *generator) countStruct(t reflect.Type, stack map[reflect.Type]bool) {
+	fields, inlineMap, _ := structFields(t)
+	for _, field := range fields {
+		g.count(field.typ, stack)
+	}
+	if inlineMap != nil {
+		g.count(inlineMap.typ, stack)
+	}
+}
+
+// structSchema returns the schema for a struct type, which is either a Ref or an inlined Object.
+func (g *generator) structSchema(t reflect.Type, tag SchemaTag) (Schema, error) {
+	if t.Name() == "" || (g.uses[t] < 2 && !g.recursive[t]) {
+		obj, err := g.object(t)
+		if err != nil {
+			return nil, err
+		}
+		obj.Description = tag.Description
+		return obj, nil
+	}
+
+	if t == g.root {
+		return Ref{Ref: "#", Description: tag.Description}, nil
+	}
+
+	name, ok := g.names[t]
+	if !ok {
+		name = g.defName(t)
+		// Register the name before generating the definition, so that the recursive fields refer to it
+		g.names[t] = name
+		obj, err := g.object(t)
+		if err != nil {
+			return nil, err
+		}
+		g.defs[name] = obj
+	}
+	ref := RefTo(name)
+	ref.Description = tag.Description
+	return ref, nil
+}
+
+// defName returns a unique name of the definition for a struct type.
+// The name is the name of the type, qualified with the package path if another type has the same name.
+func (g *generator) defName(t reflect.Type) string {
+	taken := func(name string) bool {
+		for _, n := range g.names {
+			if n == name {
+				return true
 			}
 		}
+		return false
+	}
+
+	name := sanitizeDefName(t.Name())
+	if !taken(name) {
+		return name
+	}
+	name = sanitizeDefName(t.PkgPath() + "." + t.Name())
+	for i := 2; taken(name); i++ {
+		name = sanitizeDefName(fmt.Sprintf("%s.%s%d", t.PkgPath(), t.Name(), i))
+	}
+	return name
+}
+
+// sanitizeDefName replaces the characters other than letters, digits, '_', '.' and '-' with '_',
+// e.g. the brackets of the name of a generic type.
+func sanitizeDefName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || strings.ContainsRune("_.-", r) {
+			return r
+		}
+		return '_'
+	}, name)
+}
 
-		// Parse schema tag
-		schemaTag := ParseSchemaTag(field.Tag)
-		if schemaTag.Required {
-			required = append(required, name)
+// object generates the Object for a struct type.
+func (g *generator) object(t reflect.Type) (Object, error) {
+	fields, inlineMap, err := structFields(t)
+	if err != nil {
+		return Object{}, err
+	}
+
+	obj := Object{
+		Properties: make(map[string]Schema),
+	}
+
+	var required []string
+	for _, field := range fields {
+		if field.tag.Required {
+			required = append(required, field.name)
 		}
 
 		// Generate schema for the field
-		schema, err := generateSchema(field.Type, schemaTag)
-		if err != nil {
-			return Object{}, fmt.Errorf("field %s: %w", field.Name, err)
+		var schema Schema
+		if field.quoted {
+			schema = String{Description: field.tag.Description}
+		} else {
+			schema, err = g.generateSchema(field.typ, field.tag)
+			if err != nil {
+				return Object{}, fmt.Errorf("field %s: %w", field.name, err)
+			}
 		}
 
-		obj.Properties[name] = schema
+		obj.Properties[field.name] = schema
+	}
+
+	if inlineMap != nil {
+		schema, err := g.generateSchema(inlineMap.typ, inlineMap.tag)
+		if err != nil {
+			return Object{}, fmt.Errorf("field %s: %w", inlineMap.name, err)
+		}
+		switch schema := schema.(type) {
+		case Map:
+			obj.AdditionalProperties = schema.AdditionalProperties
+		case Object:
+			obj.AdditionalProperties = schema.AdditionalProperties
+			obj.PropertyNames = schema.PropertyNames
+		}
 	}
 
 	if len(required) > 0 {
@@ -95,44 +309,232 @@ func fromStructType(t reflect.Type) (Object, error) {
 	return obj, nil
 }
 
+// customSchema returns the schema of the type which isn't generated from its kind:
+// the schema supplied by Schemer, the one of a builtin type, or the one of a type encoded by its own marshaler.
+func customSchema(t reflect.Type) (Schema, bool) {
+	// The methods of a pointer are resolved for the element type
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Interface {
+		return nil, false
+	}
+
+	switch {
+	case t.Implements(schemerType):
+		return reflect.Zero(t).Interface().(Schemer).JSONSchema(), true
+	case reflect.PointerTo(t).Implements(schemerType):
+		return reflect.New(t).Interface().(Schemer).JSONSchema(), true
+	}
+
+	if s, ok := builtinSchemas[t]; ok {
+		return s, true
+	}
+
+	// The enum values describe the encoding of an Enumer better than its marshaler
+	implements := func(u reflect.Type) bool {
+		return t.Implements(u) || reflect.PointerTo(t).Implements(u)
+	}
+	if implements(enumerType) {
+		return nil, false
+	}
+	switch {
+	case implements(jsonMarshalerType):
+		return Any{}, true
+	case implements(textMarshalerType):
+		return String{}, true
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return String{Pattern: base64Pattern}, true
+	}
+	return nil, false
+}
+
 // generateSchema generates a Schema for the given type
-func generateSchema(t reflect.Type, tag SchemaTag) (Schema, error) {
+func (g *generator) generateSchema(t reflect.Type, tag SchemaTag) (Schema, error) {
+	if tag.Enum == nil {
+		if s, ok := customSchema(t); ok {
+			if tag.Description != "" {
+				s = withDescription(s, tag.Description)
+			}
+			return s, nil
+		}
+	}
+
+	// The enum of a pointer is resolved for the element type
+	var enum []any
+	if t.Kind() != reflect.Ptr {
+		enum = enumValues(t, tag)
+	}
+	if enum != nil {
+		switch t.Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+		default:
+			return nil, fmt.Errorf("enum is not supported for %s", t.Kind())
+		}
+	}
+
 	switch t.Kind() {
 	case reflect.String:
-		return String{Description: tag.Description}, nil
+		values, err := stringEnum(enum)
+		if err != nil {
+			return nil, err
+		}
+		return String{Description: tag.Description, Enum: values}, nil
 	case reflect.Bool:
 		return Boolean{Description: tag.Description}, nil
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return Integer{Description: tag.Description}, nil
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return Integer{Description: tag.Description}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		values, err := integerEnum(enum)
+		if err != nil {
+			return nil, err
+		}
+		return Integer{Description: tag.Description, Enum: values}, nil
 	case reflect.Float32, reflect.Float64:
-		return Number{Description: tag.Description}, nil
+		values, err := numberEnum(enum)
+		if err != nil {
+			return nil, err
+		}
+		return Number{Description: tag.Description, Enum: values}, nil
 	case reflect.Slice, reflect.Array:
-		items, err := generateSchema(t.Elem(), SchemaTag{})
+		items, err := g.generateSchema(t.Elem(), SchemaTag{})
 		if err != nil {
 			return nil, fmt.Errorf("array items: %w", err)
 		}
 		return Array{Items: items, Description: tag.Description}, nil
 	case reflect.Map:
-		if t.Key().Kind() != reflect.String {
-			return nil, fmt.Errorf("map key must be string")
-		}
-		additionalProperties, err := generateSchema(t.Elem(), SchemaTag{})
+		additionalProperties, err := g.generateSchema(t.Elem(), SchemaTag{})
 		if err != nil {
 			return nil, fmt.Errorf("map values: %w", err)
 		}
-		return Map{AdditionalProperties: additionalProperties, Description: tag.Description}, nil
+		switch key := t.Key(); {
+		case key.Kind() == reflect.String, key.Implements(textMarshalerType):
+			return Map{AdditionalProperties: additionalProperties, Description: tag.Description}, nil
+		case key.Kind() >= reflect.Int && key.Kind() <= reflect.Uint64:
+			// The integer keys are encoded as decimal strings
+			return Object{
+				Description:          tag.Description,
+				AdditionalProperties: additionalProperties,
+				PropertyNames:        String{Pattern: integerKeyPattern},
+			}, nil
+		default:
+			return nil, fmt.Errorf("map key must be string, integer or encoding.TextMarshaler")
+		}
 	case reflect.Struct:
-		obj, err := fromStructType(t)
+		schema, err := g.structSchema(t, tag)
 		if err != nil {
 			return nil, fmt.Errorf("nested struct: %w", err)
 		}
-		obj.Description = tag.Description
-		return obj, nil
+		return schema, nil
 	case reflect.Ptr:
-		return generateSchema(t.Elem(), tag)
+		return g.generateSchema(t.Elem(), tag)
+	case reflect.Interface:
+		return Any{Description: tag.Description}, nil
 	default:
 		return nil, fmt.Errorf("unsupported type: %s", t.Kind())
 	}
 }
+
+// enumValues returns the enum values for the type.
+// The enum of the struct tag takes precedence over the one of the Enumer implemented by the type.
+// enumValues returns nil if neither of them is given.
+func enumValues(t reflect.Type, tag SchemaTag) []any {
+	if tag.Enum != nil {
+		values := make([]any, len(tag.Enum))
+		for i, v := range tag.Enum {
+			values[i] = v
+		}
+		return values
+	}
+
+	switch {
+	case t.Implements(enumerType):
+		return reflect.Zero(t).Interface().(Enumer).JSONSchemaEnum()
+	case reflect.PointerTo(t).Implements(enumerType):
+		return reflect.New(t).Interface().(Enumer).JSONSchemaEnum()
+	default:
+		return nil
+	}
+}
+
+// stringEnum converts the enum values to strings.
+func stringEnum(values []any) ([]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+
+	enum := make([]string, len(values))
+	for i, v := range values {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.String {
+			return nil, fmt.Errorf("enum value %v is not a string", v)
+		}
+		enum[i] = rv.String()
+	}
+	return enum, nil
+}
+
+// integerEnum converts the enum values to integers.
+// A string value, which comes from the struct tag, is parsed as an integer.
+func integerEnum(values []any) ([]int64, error) {
+	if values == nil {
+		return nil, nil
+	}
+
+	enum := make([]int64, len(values))
+	for i, v := range values {
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			enum[i] = rv.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if rv.Uint() > math.MaxInt64 {
+				return nil, fmt.Errorf("enum value %v overflows int64", v)
+			}
+			enum[i] = int64(rv.Uint())
+		case reflect.Float32, reflect.Float64:
+			f := rv.Float()
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return nil, fmt.Errorf("enum value %v is not an integer", v)
+			}
+			enum[i] = int64(f)
+		case reflect.String:
+			n, err := strconv.ParseInt(rv.String(), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("enum value %q is not an integer", rv.String())
+			}
+			enum[i] = n
+		default:
+			return nil, fmt.Errorf("enum value %v is not an integer", v)
+		}
+	}
+	return enum, nil
+}
+
+// numberEnum converts the enum values to numbers.
+// A string value, which comes from the struct tag, is parsed as a number.
+func numberEnum(values []any) ([]float64, error) {
+	if values == nil {
+		return nil, nil
+	}
+
+	enum := make([]float64, len(values))
+	for i, v := range values {
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			enum[i] = float64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			enum[i] = float64(rv.Uint())
+		case reflect.Float32, reflect.Float64:
+			enum[i] = rv.Float()
+		case reflect.String:
+			f, err := strconv.ParseFloat(rv.String(), 64)
+			if err != nil {
+				return nil, fmt.Errorf("enum value %q is not a number", rv.String())
+			}
+			enum[i] = f
+		default:
+			return nil, fmt.Errorf("enum value %v is not a number", v)
+		}
+	}
+	return enum, nil
+}
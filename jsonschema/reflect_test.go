@@ -2,8 +2,11 @@ package jsonschema
 
 import (
 	"encoding/json"
+	"net/netip"
+	"net/url"
 	"reflect"
 	"testing"
+	"time"
 )
 
 type testStruct struct {
@@ -107,12 +110,17 @@ func TestParseSchemaTag(t *testing.T) {
 			tag:  `jsonschema:"required,description=test description"`,
 			want: SchemaTag{Required: true, Description: "test description"},
 		},
+		{
+			name: "enum",
+			tag:  `jsonschema:"required,enum=a|b|c"`,
+			want: SchemaTag{Required: true, Enum: []string{"a", "b", "c"}},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got := ParseSchemaTag(tt.tag)
-			if got.Required != tt.want.Required || got.Description != tt.want.Description {
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("ParseSchemaTag() = %v, want %v", got, tt.want)
 			}
 		})
@@ -218,3 +226,370 @@ func TestFromStructType(t *testing.T) {
 		})
 	}
 }
+
+type color string
+
+func (color) JSONSchemaEnum() []any {
+	return []any{"red", "green", "blue"}
+}
+
+type level int
+
+func (*level) JSONSchemaEnum() []any {
+	return []any{1, 2, 3}
+}
+
+type enumStruct struct {
+	Color    color    `json:"color"`
+	ColorPtr *color   `json:"colorPtr"`
+	Colors   []color  `json:"colors"`
+	Level    level    `json:"level"`
+	Size     string   `json:"size" jsonschema:"enum=small|medium|large"`
+	Count    int      `json:"count" jsonschema:"enum=1|2|3"`
+	Ratio    float64  `json:"ratio" jsonschema:"enum=0.5|1.5"`
+	Override color    `json:"override" jsonschema:"enum=red"`
+	Tags     []string `json:"tags"`
+}
+
+func TestFromStruct_Enum(t *testing.T) {
+	got, err := FromStruct(enumStruct{})
+	if err != nil {
+		t.Fatalf("FromStruct() error = %v", err)
+	}
+
+	want := Object{
+		Properties: map[string]Schema{
+			"color":    String{Enum: []string{"red", "green", "blue"}},
+			"colorPtr": String{Enum: []string{"red", "green", "blue"}},
+			"colors":   Array{Items: String{Enum: []string{"red", "green", "blue"}}},
+			"level":    Integer{Enum: []int64{1, 2, 3}},
+			"size":     String{Enum: []string{"small", "medium", "large"}},
+			"count":    Integer{Enum: []int64{1, 2, 3}},
+			"ratio":    Number{Enum: []float64{0.5, 1.5}},
+			"override": String{Enum: []string{"red"}},
+			"tags":     Array{Items: String{}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromStruct() = %v, want %v", got, want)
+	}
+}
+
+func TestFromStruct_EnumErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input any
+	}{
+		{
+			name: "invalid integer in tag",
+			input: struct {
+				Count int `json:"count" jsonschema:"enum=1|two"`
+			}{},
+		},
+		{
+			name: "invalid number in tag",
+			input: struct {
+				Ratio float64 `json:"ratio" jsonschema:"enum=half"`
+			}{},
+		},
+		{
+			name: "enum on unsupported type",
+			input: struct {
+				Flag bool `json:"flag" jsonschema:"enum=true"`
+			}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := FromStruct(tt.input); err == nil {
+				t.Errorf("FromStruct() error = nil, want error")
+			}
+		})
+	}
+}
+
+type treeNode struct {
+	Value    string     `json:"value" jsonschema:"required"`
+	Children []treeNode `json:"children"`
+}
+
+type address struct {
+	City string `json:"city"`
+}
+
+type recursiveStruct struct {
+	Tree    treeNode         `json:"tree" jsonschema:"description=The tree"`
+	Home    address          `json:"home"`
+	Work    *address         `json:"work"`
+	Parent  *recursiveStruct `json:"parent"`
+	Inlined nestedStruct     `json:"inlined"`
+}
+
+func TestFromStruct_Defs(t *testing.T) {
+	got, err := FromStruct(recursiveStruct{})
+	if err != nil {
+		t.Fatalf("FromStruct() error = %v", err)
+	}
+
+	want := Object{
+		Properties: map[string]Schema{
+			"tree":   Ref{Ref: "#/$defs/treeNode", Description: "The tree"},
+			"home":   Ref{Ref: "#/$defs/address"},
+			"work":   Ref{Ref: "#/$defs/address"},
+			"parent": Ref{Ref: "#"},
+			"inlined": Object{
+				Properties: map[string]Schema{
+					"value": String{},
+				},
+				Required: []string{"value"},
+			},
+		},
+		Defs: map[string]Schema{
+			"treeNode": Object{
+				Properties: map[string]Schema{
+					"value":    String{},
+					"children": Array{Items: Ref{Ref: "#/$defs/treeNode"}},
+				},
+				Required: []string{"value"},
+			},
+			"address": Object{
+				Properties: map[string]Schema{
+					"city": String{},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromStruct() = %v, want %v", got, want)
+	}
+
+	if err := got.Validate(json.RawMessage(`{"tree": {"value": "a", "children": [{"value": "b"}]}, "parent": {"home": {"city": "Tokyo"}}}`)); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := got.Validate(json.RawMessage(`{"tree": {"value": "a", "children": [{}]}}`)); err == nil {
+		t.Errorf("Validate() error = nil, want error")
+	}
+}
+
+type Metadata struct {
+	Labels map[string]string `json:"labels"`
+	Owner  string            `json:"owner" jsonschema:"required"`
+}
+
+type inlineStruct struct {
+	Name     string             `json:"name" jsonschema:"required"`
+	Metadata                    // embedded without a JSON name
+	Common   commonOptions      `jsonschema:"inline"`
+	Extra    map[string]float64 `json:"-" jsonschema:"inline"`
+}
+
+type commonOptions struct {
+	Verbose bool   `json:"verbose"`
+	Name    string `json:"name"`
+}
+
+type SelfInlined struct {
+	*SelfInlined `jsonschema:"inline"`
+}
+
+func TestFromStruct_Inline(t *testing.T) {
+	got, err := FromStruct(inlineStruct{})
+	if err != nil {
+		t.Fatalf("FromStruct() error = %v", err)
+	}
+
+	want := Object{
+		Properties: map[string]Schema{
+			"name":    String{},
+			"labels":  Map{AdditionalProperties: String{}},
+			"owner":   String{},
+			"verbose": Boolean{},
+		},
+		Required:             []string{"name", "owner"},
+		AdditionalProperties: Number{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromStruct() = %v, want %v", got, want)
+	}
+
+	if err := got.Validate(json.RawMessage(`{"name": "a", "owner": "b", "score": 1.5}`)); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := got.Validate(json.RawMessage(`{"name": "a", "owner": "b", "score": "high"}`)); err == nil {
+		t.Errorf("Validate() error = nil, want error")
+	}
+}
+
+func TestFromStruct_InlineErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input any
+	}{
+		{
+			name: "inline scalar",
+			input: struct {
+				Name string `jsonschema:"inline"`
+			}{},
+		},
+		{
+			name: "inline map with unsupported key",
+			input: struct {
+				Values map[bool]string `jsonschema:"inline"`
+			}{},
+		},
+		{
+			name: "more than one inline map",
+			input: struct {
+				A map[string]string `jsonschema:"inline"`
+				B map[string]string `jsonschema:"inline"`
+			}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := FromStruct(tt.input); err == nil {
+				t.Errorf("FromStruct() error = nil, want error")
+			}
+		})
+	}
+}
+
+type Embedded struct {
+	ID    string `json:"id" jsonschema:"required"`
+	Name  string `json:"name"`
+	Extra string `json:"extra"`
+}
+
+type other struct {
+	Extra string `json:"extra"`
+	Note  string
+}
+
+type pointEmbedded struct {
+	X int `json:"x"`
+}
+
+type visibilityStruct struct {
+	Embedded
+	*other
+	pointEmbedded
+	Named   Embedded `json:"named"`
+	Name    string   `json:"name" jsonschema:"description=The outer name"`
+	Skipped string   `json:"-"`
+	Dash    string   `json:"-,"`
+	Count   int      `json:"count,string"`
+	Flag    bool     `json:",omitempty,string"`
+	hidden  string
+}
+
+func TestFromStruct_FieldVisibility(t *testing.T) {
+	got, err := FromStruct(visibilityStruct{})
+	if err != nil {
+		t.Fatalf("FromStruct() error = %v", err)
+	}
+
+	want := Object{
+		Properties: map[string]Schema{
+			"id":   String{},
+			"Note": String{},
+			"x":    Integer{},
+			"named": Object{
+				Properties: map[string]Schema{
+					"id":    String{},
+					"name":  String{},
+					"extra": String{},
+				},
+				Required: []string{"id"},
+			},
+			"name":  String{Description: "The outer name"},
+			"-":     String{},
+			"count": String{},
+			"Flag":  String{},
+		},
+		Required: []string{"id"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromStruct() = %v, want %v", got, want)
+	}
+}
+
+type customID int
+
+func (customID) JSONSchema() Schema {
+	return String{Pattern: "^id-[0-9]+$"}
+}
+
+type customPoint struct {
+	X, Y float64
+}
+
+func (*customPoint) JSONSchema() Schema {
+	return Array{Items: Number{}, MinItems: 2, MaxItems: 2}
+}
+
+type textValue struct {
+	value string
+}
+
+func (v textValue) MarshalText() ([]byte, error) {
+	return []byte(v.value), nil
+}
+
+type typesStruct struct {
+	Time     time.Time         `json:"time" jsonschema:"description=The creation time"`
+	TimePtr  *time.Time        `json:"timePtr"`
+	Duration time.Duration     `json:"duration"`
+	Raw      json.RawMessage   `json:"raw"`
+	Bytes    []byte            `json:"bytes"`
+	URL      *url.URL          `json:"url"`
+	Addr     netip.Addr        `json:"addr"`
+	Any      any               `json:"any"`
+	Error    error             `json:"error"`
+	ID       customID          `json:"id" jsonschema:"description=The ID"`
+	Point    customPoint       `json:"point"`
+	Text     textValue         `json:"text"`
+	ByIndex  map[int]string    `json:"byIndex"`
+	ByText   map[textValue]int `json:"byText"`
+	SelfInlined
+}
+
+func TestFromStruct_Types(t *testing.T) {
+	got, err := FromStruct(typesStruct{})
+	if err != nil {
+		t.Fatalf("FromStruct() error = %v", err)
+	}
+
+	want := Object{
+		Properties: map[string]Schema{
+			"time":     String{Format: "date-time", Description: "The creation time"},
+			"timePtr":  String{Format: "date-time"},
+			"duration": Integer{},
+			"raw":      Any{},
+			"bytes":    String{Pattern: base64Pattern},
+			"url":      String{Format: "uri"},
+			"addr":     AnyOf{Schemas: []Schema{String{Format: "ipv4"}, String{Format: "ipv6"}}},
+			"any":      Any{},
+			"error":    Any{},
+			"id":       String{Pattern: "^id-[0-9]+$", Description: "The ID"},
+			"point":    Array{Items: Number{}, MinItems: 2, MaxItems: 2},
+			"text":     String{},
+			"byIndex": Object{
+				AdditionalProperties: String{},
+				PropertyNames:        String{Pattern: integerKeyPattern},
+			},
+			"byText": Map{AdditionalProperties: Integer{}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromStruct() = %v, want %v", got, want)
+	}
+
+	if err := got.Validate(json.RawMessage(`{"time": "2024-01-02T03:04:05Z", "bytes": "aGVsbG8=", "any": [1, "a"], "id": "id-1", "byIndex": {"-1": "a"}}`)); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := got.Validate(json.RawMessage(`{"time": "yesterday", "bytes": "!", "id": "1", "byIndex": {"a": "b"}}`)); err == nil {
+		t.Errorf("Validate() error = nil, want error")
+	}
+}
@@ -9,5 +9,27 @@ type Schema interface {
 
 type SchemaValidator interface {
 	Validate(v json.RawMessage) error
-	validate(v any) error
+	validate(c *validationContext, location string, v any) []Violation
+}
+
+// validationContext is the state shared while validating a value against a schema document.
+type validationContext struct {
+	// root is the root schema of the document, against which the references are resolved
+	root Schema
+	// defs are the definitions of the root schema, whatever its type
+	defs map[string]Schema
+	// depth is the number of the references being followed
+	depth int
+}
+
+// newValidationContext returns a validationContext for the schema document.
+func newValidationContext(root Schema) *validationContext {
+	c := &validationContext{root: root}
+	switch root := root.(type) {
+	case Object:
+		c.defs = root.Defs
+	case AllOf:
+		c.defs = root.Defs
+	}
+	return c
 }
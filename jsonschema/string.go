@@ -3,13 +3,27 @@ package jsonschema
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"unicode/utf8"
 )
 
 // String is a JSON schema string.
+//
+// MinLength and MaxLength count Unicode code points, not bytes.
 type String struct {
 	Description string `json:"description,omitempty"`
 	MinLength   int    `json:"minLength,omitempty"`
 	MaxLength   int    `json:"maxLength,omitempty"`
+	// Pattern is an ECMA-262 regular expression the string must match.
+	// The expression is not anchored, and the features that RE2 lacks, such as lookarounds
+	// and backreferences, are not supported.
+	Pattern string `json:"pattern,omitempty"`
+	// Format is the format of the string, e.g. "date-time".
+	// Unknown formats are not validated; see RegisterFormat for the known formats.
+	Format string `json:"format,omitempty"`
+	// Enum is the list of the allowed values, or nil to allow any string.
+	// An empty non-nil Enum allows no string.
+	Enum []string `json:"enum,omitzero"`
 }
 
 // Validate validates the string against the JSON schema.
@@ -19,29 +33,58 @@ func (s String) Validate(v json.RawMessage) error {
 		return fmt.Errorf("unmarshal: %w", err)
 	}
 
-	return s.validate(m)
+	return validateValue(s, m)
 }
 
 // validate validates the string against the JSON schema.
-func (s String) validate(v any) error {
+func (s String) validate(c *validationContext, location string, v any) []Violation {
 	str, ok := v.(string)
 	if !ok {
-		return fmt.Errorf("value is not a string")
+		return []Violation{newViolation(location, "type", "value is not a string")}
 	}
 
-	if s.MinLength > 0 && len(str) < s.MinLength {
-		return fmt.Errorf("string is too short")
+	var violations []Violation
+
+	if s.MinLength > 0 && utf8.RuneCountInString(str) < s.MinLength {
+		violations = append(violations, newViolation(location, "minLength", "string is too short"))
+	}
+
+	if s.MaxLength > 0 && utf8.RuneCountInString(str) > s.MaxLength {
+		violations = append(violations, newViolation(location, "maxLength", "string is too long"))
+	}
+
+	if s.Pattern != "" {
+		re, err := compilePattern(s.Pattern)
+		if err != nil {
+			violations = append(violations, newViolation(location, "pattern", "%s", err))
+		} else if !re.MatchString(str) {
+			violations = append(violations, newViolation(location, "pattern", "string does not match pattern %s", s.Pattern))
+		}
 	}
 
-	if s.MaxLength > 0 && len(str) > s.MaxLength {
-		return fmt.Errorf("string is too long")
+	if s.Format != "" {
+		if f, ok := lookupFormat(s.Format); ok {
+			if err := f(str); err != nil {
+				violations = append(violations, newViolation(location, "format", "string is not a valid %s: %s", s.Format, err))
+			}
+		}
 	}
 
-	return nil
+	if s.Enum != nil && !slices.Contains(s.Enum, str) {
+		violations = append(violations, newViolation(location, "enum", "value is not one of the enum values"))
+	}
+
+	return violations
 }
 
 // MarshalJSON implements the json.Marshaler interface.
 func (s String) MarshalJSON() ([]byte, error) {
+	if s.Pattern != "" {
+		if _, err := compilePattern(s.Pattern); err != nil {
+			return nil, err
+		}
+	}
+
 	type stringSchema String
 
 	return json.Marshal(struct {
@@ -15,11 +15,15 @@ func TestString_Validate(t *testing.T) {
 		{"too short", String{MinLength: 3, MaxLength: 5}, "te", true},
 		{"too long", String{MinLength: 3, MaxLength: 5}, "testing", true},
 		{"not a string", String{MinLength: 3, MaxLength: 5}, 123, true},
+		{"in enum", String{Enum: []string{"a", "b"}}, "a", false},
+		{"not in enum", String{Enum: []string{"a", "b"}}, "c", true},
+		{"length in code points", String{MinLength: 3, MaxLength: 3}, "日本語", false},
+		{"too long in code points", String{MaxLength: 2}, "日本語", true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := tt.schema.validate(tt.value)
+			err := validateValue(tt.schema, tt.value)
 			if (err != nil) != tt.expectErr {
 				t.Errorf("expected error: %v, got: %v", tt.expectErr, err)
 			}
@@ -37,6 +41,8 @@ func TestString_MarshalJSON(t *testing.T) {
 		{"only min length", String{MinLength: 3}, `{"minLength":3,"type":"string"}`},
 		{"only max length", String{MaxLength: 5}, `{"maxLength":5,"type":"string"}`},
 		{"no constraints", String{}, `{"type":"string"}`},
+		{"enum", String{Enum: []string{"a", "b"}}, `{"enum":["a","b"],"type":"string"}`},
+		{"pattern and format", String{Pattern: "^[a-z]+$", Format: "hostname"}, `{"format":"hostname","pattern":"^[a-z]+$","type":"string"}`},
 	}
 
 	for _, tt := range tests {
@@ -48,4 +54,10 @@ func TestString_MarshalJSON(t *testing.T) {
 			assertJSONEqual(t, tt.expected, string(data))
 		})
 	}
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		if _, err := (String{Pattern: "(?=a)"}).MarshalJSON(); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
 }
@@ -0,0 +1,228 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+var (
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	rawMessageType      = reflect.TypeFor[json.RawMessage]()
+	urlType             = reflect.TypeFor[url.URL]()
+)
+
+// Unmarshal decodes the JSON data into v, following the schema generated by FromStruct.
+//
+// encoding/json doesn't decode some values as described by the schema, so Unmarshal decodes them itself:
+//   - the properties of a struct field tagged with jsonschema:"inline" are decoded into the field,
+//     and the properties matching no field are decoded into the inline map field,
+//   - url.URL is decoded from a string by url.Parse.
+//
+// The types containing none of them, and the types implementing json.Unmarshaler or Schemer, are decoded by json.Unmarshal.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return json.Unmarshal(data, v)
+	}
+	return unmarshalValue(data, rv.Elem())
+}
+
+// unmarshalValue decodes data into the addressable value v.
+func unmarshalValue(data []byte, v reflect.Value) error {
+	if !needsUnmarshal(v.Type(), map[reflect.Type]bool{}) || bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return json.Unmarshal(data, v.Addr().Interface())
+	}
+
+	switch {
+	case v.Type() == urlType:
+		return unmarshalURL(data, v)
+	case v.Kind() == reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshalValue(data, v.Elem())
+	case v.Kind() == reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		v.Set(reflect.MakeSlice(v.Type(), len(items), len(items)))
+		for i, item := range items {
+			if err := unmarshalValue(item, v.Index(i)); err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		return nil
+	case v.Kind() == reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		v.SetZero()
+		for i, item := range items[:min(len(items), v.Len())] {
+			if err := unmarshalValue(item, v.Index(i)); err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+		return nil
+	case v.Kind() == reflect.Map:
+		return unmarshalMap(data, v)
+	default:
+		return unmarshalStruct(data, v)
+	}
+}
+
+// unmarshalURL decodes a string into the url.URL v.
+func unmarshalURL(data []byte, v reflect.Value) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	v.Set(reflect.ValueOf(*u))
+	return nil
+}
+
+// unmarshalMap decodes an object into the map v, keeping the existing entries as json.Unmarshal does.
+func unmarshalMap(data []byte, v reflect.Value) error {
+	// The keys are converted by json.Unmarshal
+	raw := reflect.New(reflect.MapOf(v.Type().Key(), rawMessageType))
+	if err := json.Unmarshal(data, raw.Interface()); err != nil {
+		return err
+	}
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+	iter := raw.Elem().MapRange()
+	for iter.Next() {
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := unmarshalValue(iter.Value().Bytes(), elem); err != nil {
+			return fmt.Errorf("key %v: %w", iter.Key(), err)
+		}
+		v.SetMapIndex(iter.Key(), elem)
+	}
+	return nil
+}
+
+// unmarshalStruct decodes an object into the struct v by its properties, as the schema generated by FromStruct.
+func unmarshalStruct(data []byte, v reflect.Value) error {
+	fields, inlineMap, err := structFields(v.Type())
+	if err != nil {
+		return err
+	}
+
+	var props map[string]json.RawMessage
+	if err := json.Unmarshal(data, &props); err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		raw, ok := props[f.name]
+		if !ok {
+			continue
+		}
+		delete(props, f.name)
+
+		fv, err := fieldByIndex(v, f.index)
+		if err != nil {
+			return err
+		}
+		if f.quoted {
+			var s string
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return fmt.Errorf("field %s: %w", f.name, err)
+			}
+			raw = json.RawMessage(s)
+		}
+		if err := unmarshalValue(raw, fv); err != nil {
+			return fmt.Errorf("field %s: %w", f.name, err)
+		}
+	}
+
+	if inlineMap == nil || len(props) == 0 {
+		return nil
+	}
+
+	// The remaining properties are the additional properties held by the inline map
+	rest, err := json.Marshal(props)
+	if err != nil {
+		return err
+	}
+	mv, err := fieldByIndex(v, inlineMap.index)
+	if err != nil {
+		return err
+	}
+	if err := unmarshalValue(rest, mv); err != nil {
+		return fmt.Errorf("field %s: %w", inlineMap.name, err)
+	}
+	return nil
+}
+
+// needsUnmarshal reports whether json.Unmarshal doesn't decode t as the schema,
+// that is, t is url.URL or a struct with the fields tagged with jsonschema:"inline",
+// or t contains such a type as its element or field.
+func needsUnmarshal(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if t == urlType {
+		return true
+	}
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		if t.Kind() != reflect.Ptr && (t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType)) {
+			return false
+		}
+		if _, ok := customSchema(t); ok {
+			return false
+		}
+		return needsUnmarshal(t.Elem(), visited)
+	case reflect.Struct:
+	default:
+		return false
+	}
+
+	if _, ok := customSchema(t); ok || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return false
+	}
+
+	fields, inlineMap, err := structFields(t)
+	if err != nil {
+		return false
+	}
+	if inlineMap != nil {
+		return true
+	}
+	for _, f := range fields {
+		if f.inlined || needsUnmarshal(f.typ, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldByIndex returns the field of the struct v for the index sequence, as in reflect.Value.FieldByIndex,
+// allocating the nil pointers to the embedded structs on the way.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct: %v", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
@@ -0,0 +1,183 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type inlineParent struct {
+	Child   inlineStruct  `json:"child"`
+	Pointer *inlineStruct `json:"pointer"`
+}
+
+type urlStruct struct {
+	URL     url.URL            `json:"url"`
+	Pointer *url.URL           `json:"pointer"`
+	List    []url.URL          `json:"list"`
+	Map     map[string]url.URL `json:"map"`
+	Items   []inlineStruct     `json:"items"`
+}
+
+type quotedInline struct {
+	Counts `jsonschema:"inline"`
+}
+
+type Counts struct {
+	Count int `json:"count,string"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		got   any
+		want  any
+	}{
+		{
+			name:  "inline struct and map",
+			input: `{"name": "a", "owner": "b", "labels": {"k": "v"}, "verbose": true, "score": 1.5, "rank": 2}`,
+			got:   &inlineStruct{},
+			want: &inlineStruct{
+				Name:     "a",
+				Metadata: Metadata{Owner: "b", Labels: map[string]string{"k": "v"}},
+				Common:   commonOptions{Verbose: true},
+				Extra:    map[string]float64{"score": 1.5, "rank": 2},
+			},
+		},
+		{
+			name:  "without additional properties",
+			input: `{"name": "a", "owner": "b"}`,
+			got:   &inlineStruct{},
+			want:  &inlineStruct{Name: "a", Metadata: Metadata{Owner: "b"}},
+		},
+		{
+			name:  "nested struct with inline fields",
+			input: `{"child": {"name": "a", "verbose": true, "score": 1}, "pointer": {"owner": "b", "rank": 2}}`,
+			got:   &inlineParent{},
+			want: &inlineParent{
+				Child:   inlineStruct{Name: "a", Common: commonOptions{Verbose: true}, Extra: map[string]float64{"score": 1}},
+				Pointer: &inlineStruct{Metadata: Metadata{Owner: "b"}, Extra: map[string]float64{"rank": 2}},
+			},
+		},
+		{
+			name:  "null pointer to struct with inline fields",
+			input: `{"pointer": null}`,
+			got:   &inlineParent{Pointer: &inlineStruct{}},
+			want:  &inlineParent{},
+		},
+		{
+			name:  "quoted inline field",
+			input: `{"count": "3"}`,
+			got:   &quotedInline{},
+			want:  &quotedInline{Counts: Counts{Count: 3}},
+		},
+		{
+			name:  "url",
+			input: `{"url": "https://example.com/a?b=c", "pointer": "mailto:a@example.com", "list": ["https://example.com"], "map": {"k": "file:///tmp"}}`,
+			got:   &urlStruct{},
+			want: &urlStruct{
+				URL:     url.URL{Scheme: "https", Host: "example.com", Path: "/a", RawQuery: "b=c"},
+				Pointer: &url.URL{Scheme: "mailto", Opaque: "a@example.com"},
+				List:    []url.URL{{Scheme: "https", Host: "example.com"}},
+				Map:     map[string]url.URL{"k": {Scheme: "file", Path: "/tmp"}},
+			},
+		},
+		{
+			name:  "slice of structs with inline fields",
+			input: `{"items": [{"name": "a", "verbose": true, "score": 1}]}`,
+			got:   &urlStruct{},
+			want: &urlStruct{
+				Items: []inlineStruct{{Name: "a", Common: commonOptions{Verbose: true}, Extra: map[string]float64{"score": 1}}},
+			},
+		},
+		{
+			name:  "struct without inline fields",
+			input: `{"string": "a", "NESTED": {"value": "b"}}`,
+			got:   &testStruct{},
+			want:  &testStruct{String: "a", Nested: nestedStruct{Value: "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal([]byte(tt.input), tt.got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("Unmarshal() = %+v, want %+v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "not an object",
+			input: `[1]`,
+		},
+		{
+			name:  "invalid additional property",
+			input: `{"name": "a", "score": "high"}`,
+		},
+		{
+			name:  "invalid inline property",
+			input: `{"verbose": "yes"}`,
+		},
+		{
+			name:  "invalid url",
+			input: `{"url": "http://[::1"}`,
+		},
+		{
+			name:  "url not a string",
+			input: `{"url": {"Scheme": "https"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v struct {
+				inlineStruct
+				URL url.URL `json:"url"`
+			}
+			if err := Unmarshal([]byte(tt.input), &v); err == nil {
+				t.Errorf("Unmarshal() error = nil, want error")
+			}
+		})
+	}
+}
+
+// TestUnmarshal_Validate checks that the input accepted by the schema of FromStruct is decoded by Unmarshal,
+// while json.Unmarshal drops the inline properties.
+func TestUnmarshal_Validate(t *testing.T) {
+	schema, err := FromStruct(inlineStruct{})
+	if err != nil {
+		t.Fatalf("FromStruct() error = %v", err)
+	}
+
+	input := json.RawMessage(`{"name": "a", "owner": "b", "verbose": true, "score": 1.5}`)
+	if err := schema.Validate(input); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	var got inlineStruct
+	if err := Unmarshal(input, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !got.Common.Verbose || got.Extra["score"] != 1.5 {
+		t.Errorf("Unmarshal() = %+v, want the inline properties decoded", got)
+	}
+
+	var plain inlineStruct
+	if err := json.Unmarshal(input, &plain); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if plain.Common.Verbose || plain.Extra != nil {
+		t.Errorf("json.Unmarshal() = %+v, want the inline properties dropped", plain)
+	}
+}
@@ -0,0 +1,26 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/Warashi/go-modelcontextprotocol/jsonrpc2"
+)
+
+// CancelledNotificationParams is the params of the cancelled notification.
+type CancelledNotificationParams struct {
+	// RequestID is the ID of the request to cancel.
+	RequestID jsonrpc2.ID `json:"requestId"`
+	// Reason is an optional string describing the reason for the cancellation.
+	Reason string `json:"reason,omitempty,omitzero"`
+}
+
+// NotifyCancelled sends the cancelled notification for the request to the peer.
+// It implements jsonrpc2.CancelNotifier.
+func NotifyCancelled(ctx context.Context, conn *jsonrpc2.Conn, id jsonrpc2.ID, reason string) error {
+	return jsonrpc2.Notify(ctx, conn, "notifications/cancelled", &Notification[CancelledNotificationParams]{
+		Params: CancelledNotificationParams{
+			RequestID: id,
+			Reason:    reason,
+		},
+	})
+}
@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Warashi/go-modelcontextprotocol/jsonrpc2"
+	"github.com/Warashi/go-modelcontextprotocol/transport"
+)
+
+func TestServer_CallTimeoutNotifiesCancelled(t *testing.T) {
+	a, b := transport.NewPipe()
+
+	server := mustNewServer(t, "test", "1.0.0",
+		WithConnectionOptions(jsonrpc2.WithCallTimeout(10*time.Millisecond)),
+	)
+	go server.Serve(t.Context(), 1, a)
+
+	cancelled := make(chan CancelledNotificationParams, 1)
+	client := jsonrpc2.NewConnection(b,
+		jsonrpc2.WithHandlerFunc("slow", func(ctx context.Context, req any) (any, error) {
+			time.Sleep(50 * time.Millisecond)
+			return struct{}{}, nil
+		}),
+		jsonrpc2.WithHandlerFunc("notifications/cancelled", func(ctx context.Context, req *Notification[CancelledNotificationParams]) (any, error) {
+			cancelled <- req.Params
+			return nil, nil
+		}),
+	)
+	client.Open()
+
+	var conn *jsonrpc2.Conn
+	for conn == nil {
+		server.mu.Lock()
+		if sess, ok := server.connections[1]; ok {
+			conn = sess.conn
+		}
+		server.mu.Unlock()
+	}
+
+	_, err := jsonrpc2.Call[any, any](t.Context(), conn, "slow", struct{}{})
+	var timeoutErr *jsonrpc2.TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected *jsonrpc2.TimeoutError, got %T: %v", err, err)
+	}
+
+	select {
+	case params := <-cancelled:
+		if params.RequestID.IsNull() {
+			t.Error("expected request ID in cancelled notification")
+		}
+		if !strings.Contains(params.Reason, "timed out") {
+			t.Errorf("reason = %q; want it to contain %q", params.Reason, "timed out")
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("cancelled notification not received")
+	}
+}
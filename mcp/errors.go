@@ -0,0 +1,186 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Warashi/go-modelcontextprotocol/jsonrpc2"
+)
+
+// CodeResourceNotFound is the error code for a resource that does not exist.
+const CodeResourceNotFound = -32002
+
+var (
+	// ErrToolNotFound is matched by errors.Is for a *ToolNotFoundError.
+	ErrToolNotFound = errors.New("tool not found")
+	// ErrInvalidParams is matched by errors.Is for an *InvalidParamsError.
+	ErrInvalidParams = errors.New("invalid params")
+	// ErrResourceNotFound is matched by errors.Is for a *ResourceNotFoundError.
+	ErrResourceNotFound = errors.New("resource not found")
+)
+
+// ToolNotFoundError is returned when the requested tool is not registered.
+// It is sent to the peer as an Invalid params error.
+type ToolNotFoundError struct {
+	// Name is the name of the requested tool.
+	Name string
+}
+
+// Error implements the error interface.
+func (e *ToolNotFoundError) Error() string {
+	return fmt.Sprintf("unknown tool: %s", e.Name)
+}
+
+// Is reports whether target is ErrToolNotFound.
+func (e *ToolNotFoundError) Is(target error) bool {
+	return target == ErrToolNotFound
+}
+
+// JSONRPCError implements jsonrpc2.ErrorCoder.
+func (e *ToolNotFoundError) JSONRPCError() jsonrpc2.Error[any] {
+	return jsonrpc2.NewError[any](jsonrpc2.CodeInvalidParams, e.Error(), map[string]any{"tool": e.Name})
+}
+
+// InvalidParamsError is returned when the parameters of a request are invalid.
+type InvalidParamsError struct {
+	// Message describes what is wrong with the parameters.
+	Message string
+	// Fields lists the parameters that failed, if known.
+	// Fields is sent to the peer as the data of the error.
+	Fields []InvalidField
+}
+
+// InvalidField describes a parameter that failed.
+type InvalidField struct {
+	// Name is the name of the parameter.
+	Name string `json:"name"`
+	// Message describes why the parameter is invalid.
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e *InvalidParamsError) Error() string {
+	return fmt.Sprintf("invalid params: %s", e.Message)
+}
+
+// Is reports whether target is ErrInvalidParams.
+func (e *InvalidParamsError) Is(target error) bool {
+	return target == ErrInvalidParams
+}
+
+// JSONRPCError implements jsonrpc2.ErrorCoder.
+func (e *InvalidParamsError) JSONRPCError() jsonrpc2.Error[any] {
+	if len(e.Fields) == 0 {
+		return jsonrpc2.NewError[any](jsonrpc2.CodeInvalidParams, e.Message, nil)
+	}
+	return jsonrpc2.NewError[any](jsonrpc2.CodeInvalidParams, e.Message, map[string]any{"fields": e.Fields})
+}
+
+// ResourceNotFoundError is returned when the requested resource does not exist.
+type ResourceNotFoundError struct {
+	// URI is the URI of the requested resource.
+	URI string
+}
+
+// Error implements the error interface.
+func (e *ResourceNotFoundError) Error() string {
+	return fmt.Sprintf("resource not found: %s", e.URI)
+}
+
+// Is reports whether target is ErrResourceNotFound.
+func (e *ResourceNotFoundError) Is(target error) bool {
+	return target == ErrResourceNotFound
+}
+
+// JSONRPCError implements jsonrpc2.ErrorCoder.
+func (e *ResourceNotFoundError) JSONRPCError() jsonrpc2.Error[any] {
+	return jsonrpc2.NewError[any](CodeResourceNotFound, "Resource not found", map[string]any{"uri": e.URI})
+}
+
+// ToolError is a failure of a tool execution, which is reported to the model in the tool call result.
+// Unlike other errors returned by a ToolHandler, the message of a ToolError is always shown to the model,
+// even if Tool.HideInternalErrors is set.
+type ToolError struct {
+	// Err is the error shown to the model.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ToolError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// ProtocolError is an error of a tool call, which is sent to the peer as a JSON-RPC 2.0 error
+// instead of a tool call result.
+// The JSON-RPC 2.0 error is derived from Err, e.g. an *InvalidParamsError is sent as an Invalid params error.
+// ToolHandler can return an error implementing jsonrpc2.ErrorCoder without wrapping it with ProtocolError.
+type ProtocolError struct {
+	// Err is the error sent to the peer.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ProtocolError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ProtocolError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError converts a JSON-RPC 2.0 error received from the peer into the MCP error type it was sent from.
+// DecodeError returns err as is if it is not one of the MCP errors.
+func DecodeError(err error) error {
+	var rpcErr jsonrpc2.Error[any]
+	if !errors.As(err, &rpcErr) {
+		return err
+	}
+
+	data, _ := rpcErr.Data.(map[string]any)
+	switch rpcErr.Code {
+	case CodeResourceNotFound:
+		uri, _ := data["uri"].(string)
+		return &ResourceNotFoundError{URI: uri}
+	case jsonrpc2.CodeInvalidParams:
+		if name, ok := data["tool"].(string); ok {
+			return &ToolNotFoundError{Name: name}
+		}
+		return &InvalidParamsError{Message: rpcErr.Message, Fields: decodeInvalidFields(data["fields"])}
+	default:
+		return err
+	}
+}
+
+// decodeInvalidFields decodes the fields of an InvalidParamsError received from the peer.
+func decodeInvalidFields(v any) []InvalidField {
+	items, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+
+	fields := make([]InvalidField, 0, len(items))
+	for _, item := range items {
+		m, _ := item.(map[string]any)
+		name, _ := m["name"].(string)
+		message, _ := m["message"].(string)
+		fields = append(fields, InvalidField{Name: name, Message: message})
+	}
+	return fields
+}
+
+// Call sends a request to the peer and waits for the response.
+// Errors sent by the peer are decoded by DecodeError.
+func Call[Result, Params any](ctx context.Context, conn *jsonrpc2.Conn, method string, params Params) (Result, error) {
+	result, err := jsonrpc2.Call[Result, any](ctx, conn, method, params)
+	if err != nil {
+		return result, DecodeError(err)
+	}
+	return result, nil
+}
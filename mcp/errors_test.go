@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Warashi/go-modelcontextprotocol/jsonrpc2"
+	"github.com/Warashi/go-modelcontextprotocol/transport"
+)
+
+func TestErrors_RoundTrip(t *testing.T) {
+	a, b := transport.NewPipe()
+
+	server := mustNewServer(t, "test", "1.0.0",
+		WithResourceReader(NewResourceReaderMux()),
+		WithCustomHandlerFunc("invalid", func(ctx context.Context, params any) (any, error) {
+			return nil, fmt.Errorf("wrapped: %w", &InvalidParamsError{Message: "name is required"})
+		}),
+	)
+	go server.Serve(t.Context(), 1, a)
+
+	client := jsonrpc2.NewConnection(b)
+	client.Open()
+	defer client.Close()
+
+	_, err := Call[ToolCallResultData](t.Context(), client, "tools/call", ToolCallRequestParams{Name: "missing"})
+	var toolErr *ToolNotFoundError
+	if !errors.As(err, &toolErr) {
+		t.Fatalf("expected *ToolNotFoundError, got %T: %v", err, err)
+	}
+	if toolErr.Name != "missing" {
+		t.Errorf("Name = %q; want %q", toolErr.Name, "missing")
+	}
+
+	_, err = Call[ReadResourceResultData](t.Context(), client, "resources/read", ReadResourceRequestParams{URI: "file://localhost/missing"})
+	if !errors.Is(err, ErrResourceNotFound) {
+		t.Fatalf("expected ErrResourceNotFound, got %T: %v", err, err)
+	}
+	var resourceErr *ResourceNotFoundError
+	if errors.As(err, &resourceErr) && resourceErr.URI != "file://localhost/missing" {
+		t.Errorf("URI = %q; want %q", resourceErr.URI, "file://localhost/missing")
+	}
+
+	_, err = Call[any](t.Context(), client, "invalid", struct{}{})
+	var paramsErr *InvalidParamsError
+	if !errors.As(err, &paramsErr) {
+		t.Fatalf("expected *InvalidParamsError, got %T: %v", err, err)
+	}
+	if paramsErr.Message != "name is required" {
+		t.Errorf("Message = %q; want %q", paramsErr.Message, "name is required")
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "resource not found",
+			err:  jsonrpc2.NewError[any](CodeResourceNotFound, "Resource not found", map[string]any{"uri": "file:///a"}),
+			want: &ResourceNotFoundError{URI: "file:///a"},
+		},
+		{
+			name: "unknown tool",
+			err:  jsonrpc2.NewError[any](jsonrpc2.CodeInvalidParams, "unknown tool: a", map[string]any{"tool": "a"}),
+			want: &ToolNotFoundError{Name: "a"},
+		},
+		{
+			name: "invalid params",
+			err:  jsonrpc2.NewError[any](jsonrpc2.CodeInvalidParams, "bad", nil),
+			want: &InvalidParamsError{Message: "bad"},
+		},
+		{
+			name: "invalid params with fields",
+			err: jsonrpc2.NewError[any](jsonrpc2.CodeInvalidParams, "bad", map[string]any{
+				"fields": []any{map[string]any{"name": "city", "message": "unknown city"}},
+			}),
+			want: &InvalidParamsError{Message: "bad", Fields: []InvalidField{{Name: "city", Message: "unknown city"}}},
+		},
+		{
+			name: "other error",
+			err:  jsonrpc2.NewError[any](jsonrpc2.CodeInternalError, "boom", nil),
+			want: jsonrpc2.NewError[any](jsonrpc2.CodeInternalError, "boom", nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DecodeError(tt.err)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeError() = %#v; want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -93,9 +93,14 @@ func (s *Server) Initialize(ctx context.Context, request *Request[Initialization
 		result.Data.Capabilities.Tools = &ToolsCapabilities{}
 	}
 
-	if (len(s.resources) > 0 || len(s.resourceTemplates) > 0) && s.resourceReader != nil {
+	if (len(s.resources) > 0 || len(s.listResourceTemplates()) > 0 || len(s.resourceProviders) > 0) && s.resourceReader != nil {
 		// we have resources and a resource reader
-		result.Data.Capabilities.Resources = &ResourcesCapabilities{}
+		result.Data.Capabilities.Resources = &ResourcesCapabilities{
+			// the updates of the resources are notified to the subscribers by NotifyResourceUpdated
+			Subscribe: true,
+			// the resources of providers may change
+			ListChanged: len(s.resourceProviders) > 0,
+		}
 	}
 
 	return result, nil
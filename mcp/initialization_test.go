@@ -94,7 +94,7 @@ func TestServer_Initialize(t *testing.T) {
 				Data: InitializationResponseData{
 					ProtocolVersion: SupportedProtocolVersion,
 					Capabilities: Capabilities{
-						Resources: &ResourcesCapabilities{},
+						Resources: &ResourcesCapabilities{Subscribe: true},
 					},
 					ServerInfo: ServerInfoData{
 						Name:    "test",
@@ -128,7 +128,7 @@ func TestServer_Initialize(t *testing.T) {
 				Data: InitializationResponseData{
 					ProtocolVersion: SupportedProtocolVersion,
 					Capabilities: Capabilities{
-						Resources: &ResourcesCapabilities{},
+						Resources: &ResourcesCapabilities{Subscribe: true},
 					},
 					ServerInfo: ServerInfoData{
 						Name:    "test",
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/Warashi/go-modelcontextprotocol/jsonrpc2"
+)
+
+// ResourceChangeKind is the kind of a change of a resource.
+type ResourceChangeKind int
+
+const (
+	// ResourceCreated means the resource is added to the list of resources.
+	ResourceCreated ResourceChangeKind = iota + 1
+	// ResourceModified means the contents of the resource are changed.
+	ResourceModified
+	// ResourceRemoved means the resource is removed from the list of resources.
+	ResourceRemoved
+)
+
+// String returns the name of the kind, e.g. "modified".
+func (k ResourceChangeKind) String() string {
+	switch k {
+	case ResourceCreated:
+		return "created"
+	case ResourceModified:
+		return "modified"
+	case ResourceRemoved:
+		return "removed"
+	default:
+		return "ResourceChangeKind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// ResourceChange is a change of a resource.
+type ResourceChange struct {
+	// URI is the URI of the changed resource.
+	URI string
+	// Kind is the kind of the change.
+	Kind ResourceChangeKind
+}
+
+// ResourceUpdatedNotificationParams is the params of the resource updated notification.
+type ResourceUpdatedNotificationParams struct {
+	// URI is the URI of the updated resource.
+	URI string `json:"uri"`
+}
+
+// SubscribeResourceRequestParams is the params of the resources/subscribe request.
+type SubscribeResourceRequestParams struct {
+	// URI is the URI of the resource to subscribe to.
+	URI string `json:"uri"`
+}
+
+// UnsubscribeResourceRequestParams is the params of the resources/unsubscribe request.
+type UnsubscribeResourceRequestParams struct {
+	// URI is the URI of the resource to unsubscribe from.
+	URI string `json:"uri"`
+}
+
+// subscribeResource returns the handler of the resources/subscribe request for the session.
+func (s *Server) subscribeResource(sess *session) func(ctx context.Context, request *Request[SubscribeResourceRequestParams]) (*Result[struct{}], error) {
+	return func(ctx context.Context, request *Request[SubscribeResourceRequestParams]) (*Result[struct{}], error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		sess.subscriptions[request.Params.URI] = struct{}{}
+		return &Result[struct{}]{}, nil
+	}
+}
+
+// unsubscribeResource returns the handler of the resources/unsubscribe request for the session.
+func (s *Server) unsubscribeResource(sess *session) func(ctx context.Context, request *Request[UnsubscribeResourceRequestParams]) (*Result[struct{}], error) {
+	return func(ctx context.Context, request *Request[UnsubscribeResourceRequestParams]) (*Result[struct{}], error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		delete(sess.subscriptions, request.Params.URI)
+		return &Result[struct{}]{}, nil
+	}
+}
+
+// NotifyResourceUpdated sends the resource updated notification for the URI to the connections subscribing to it
+// by the resources/subscribe request.
+func (s *Server) NotifyResourceUpdated(ctx context.Context, uri string) error {
+	subscribed := func(sess *session) bool {
+		_, ok := sess.subscriptions[uri]
+		return ok
+	}
+	return s.broadcast(ctx, subscribed, "notifications/resources/updated", &Notification[ResourceUpdatedNotificationParams]{
+		Params: ResourceUpdatedNotificationParams{URI: uri},
+	})
+}
+
+// NotifyResourceListChanged sends the resource list changed notification to all connections.
+func (s *Server) NotifyResourceListChanged(ctx context.Context) error {
+	return s.broadcast(ctx, nil, "notifications/resources/list_changed", &Notification[struct{}]{})
+}
+
+// NotifyResourceChange sends the notification for the change.
+// A modified resource is notified as updated to the subscribers, and a created or removed resource as a change of the list to all connections.
+// It can be used as the callback of FSResources.Watch.
+func (s *Server) NotifyResourceChange(ctx context.Context, change ResourceChange) error {
+	if change.Kind == ResourceModified {
+		return s.NotifyResourceUpdated(ctx, change.URI)
+	}
+	return s.NotifyResourceListChanged(ctx)
+}
+
+// broadcast sends the notification to the connections whose sessions satisfy filter, or to all connections if filter is nil.
+func (s *Server) broadcast(ctx context.Context, filter func(*session) bool, method string, params any) error {
+	s.mu.Lock()
+	conns := make([]*jsonrpc2.Conn, 0, len(s.connections))
+	for _, sess := range s.connections {
+		if filter == nil || filter(sess) {
+			conns = append(conns, sess.conn)
+		}
+	}
+	s.mu.Unlock()
+
+	var err error
+	for _, conn := range conns {
+		err = errors.Join(err, jsonrpc2.Notify(ctx, conn, method, params))
+	}
+	return err
+}
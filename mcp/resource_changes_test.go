@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Warashi/go-modelcontextprotocol/jsonrpc2"
+	"github.com/Warashi/go-modelcontextprotocol/transport"
+)
+
+func TestServer_NotifyResourceChange(t *testing.T) {
+	a, b := transport.NewPipe()
+
+	server := mustNewServer(t, "test", "1.0.0")
+	go server.Serve(t.Context(), 1, a)
+
+	updated := make(chan string, 1)
+	listChanged := make(chan struct{}, 1)
+	client := jsonrpc2.NewConnection(b,
+		jsonrpc2.WithHandlerFunc("notifications/resources/updated", func(ctx context.Context, req *Notification[ResourceUpdatedNotificationParams]) (any, error) {
+			updated <- req.Params.URI
+			return nil, nil
+		}),
+		jsonrpc2.WithHandlerFunc("notifications/resources/list_changed", func(ctx context.Context, req *Notification[struct{}]) (any, error) {
+			listChanged <- struct{}{}
+			return nil, nil
+		}),
+	)
+	client.Open()
+	defer client.Close()
+
+	for {
+		server.mu.Lock()
+		n := len(server.connections)
+		server.mu.Unlock()
+		if n > 0 {
+			break
+		}
+	}
+
+	subscribe := func(method, uri string) {
+		t.Helper()
+		if _, err := jsonrpc2.Call[*Result[struct{}], any](t.Context(), client, method, &Request[SubscribeResourceRequestParams]{
+			Params: SubscribeResourceRequestParams{URI: uri},
+		}); err != nil {
+			t.Fatalf("%s error = %v", method, err)
+		}
+	}
+	subscribe("resources/subscribe", "file:///a.txt")
+
+	// Only the updates of the subscribed resources are notified
+	if err := server.NotifyResourceChange(t.Context(), ResourceChange{URI: "file:///other.txt", Kind: ResourceModified}); err != nil {
+		t.Fatalf("NotifyResourceChange() error = %v", err)
+	}
+	if err := server.NotifyResourceChange(t.Context(), ResourceChange{URI: "file:///a.txt", Kind: ResourceModified}); err != nil {
+		t.Fatalf("NotifyResourceChange() error = %v", err)
+	}
+	select {
+	case uri := <-updated:
+		if uri != "file:///a.txt" {
+			t.Errorf("updated URI = %q; want %q", uri, "file:///a.txt")
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("resource updated notification not received")
+	}
+
+	subscribe("resources/unsubscribe", "file:///a.txt")
+	if err := server.NotifyResourceChange(t.Context(), ResourceChange{URI: "file:///a.txt", Kind: ResourceModified}); err != nil {
+		t.Fatalf("NotifyResourceChange() error = %v", err)
+	}
+
+	if err := server.NotifyResourceChange(t.Context(), ResourceChange{URI: "file:///b.txt", Kind: ResourceCreated}); err != nil {
+		t.Fatalf("NotifyResourceChange() error = %v", err)
+	}
+	select {
+	case <-listChanged:
+	case <-time.After(1 * time.Second):
+		t.Fatal("resource list changed notification not received")
+	}
+
+	// The notifications are sent in order, so the updates after unsubscribing would have been received by now
+	select {
+	case uri := <-updated:
+		t.Errorf("unexpected resource updated notification for %q", uri)
+	default:
+	}
+}
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+)
+
+// ResourceContentsProvider provides the contents of a resource registered with WithResourceContents.
+type ResourceContentsProvider interface {
+	ResourceContents(ctx context.Context, resource Resource) ([]IsResourceContents, error)
+}
+
+// ResourceContentsFunc is a function that implements ResourceContentsProvider.
+type ResourceContentsFunc func(ctx context.Context, resource Resource) ([]IsResourceContents, error)
+
+// ResourceContents implements ResourceContentsProvider.
+func (f ResourceContentsFunc) ResourceContents(ctx context.Context, resource Resource) ([]IsResourceContents, error) {
+	return f(ctx, resource)
+}
+
+// TextContents returns a ResourceContentsProvider that provides the static text.
+func TextContents(text string) ResourceContentsProvider {
+	return ResourceContentsFunc(func(ctx context.Context, resource Resource) ([]IsResourceContents, error) {
+		return []IsResourceContents{
+			&TextResourceContents{
+				URI:      resource.URI,
+				MimeType: resource.MimeType,
+				Text:     text,
+			},
+		}, nil
+	})
+}
+
+// BlobContents returns a ResourceContentsProvider that provides the static binary data.
+func BlobContents(blob []byte) ResourceContentsProvider {
+	return ResourceContentsFunc(func(ctx context.Context, resource Resource) ([]IsResourceContents, error) {
+		return []IsResourceContents{
+			&BlobResourceContents{
+				URI:      resource.URI,
+				MimeType: resource.MimeType,
+				Blob:     blob,
+			},
+		}, nil
+	})
+}
+
+// ReaderContents returns a ResourceContentsProvider that provides the data read from r.
+// r is read to the end on the first read of the resource, and closed if it implements io.Closer.
+// The data is provided as text if the MIME type of the resource is text/*, otherwise as binary data.
+func ReaderContents(r io.Reader) ResourceContentsProvider {
+	read := sync.OnceValues(func() ([]byte, error) {
+		if c, ok := r.(io.Closer); ok {
+			defer c.Close()
+		}
+		return io.ReadAll(r)
+	})
+
+	return ResourceContentsFunc(func(ctx context.Context, resource Resource) ([]IsResourceContents, error) {
+		b, err := read()
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(resource.MimeType, "text/") {
+			return TextContents(string(b)).ResourceContents(ctx, resource)
+		}
+		return BlobContents(b).ResourceContents(ctx, resource)
+	})
+}
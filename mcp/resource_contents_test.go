@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithResourceContents(t *testing.T) {
+	server := mustNewServer(t, "test", "1.0.0",
+		WithResourceContents(Resource{URI: "file:///text.txt", Name: "text", MimeType: "text/plain"}, TextContents("hello")),
+		WithResourceContents(Resource{URI: "file:///blob.bin", Name: "blob", MimeType: "application/octet-stream"}, BlobContents([]byte{0, 1, 2})),
+		WithResourceContents(Resource{URI: "file:///func.txt", Name: "func"}, ResourceContentsFunc(func(ctx context.Context, resource Resource) ([]IsResourceContents, error) {
+			return []IsResourceContents{&TextResourceContents{URI: resource.URI, Text: "from func"}}, nil
+		})),
+		WithResourceContents(Resource{URI: "file:///reader.md", Name: "reader", MimeType: "text/markdown"}, ReaderContents(strings.NewReader("# title"))),
+	)
+
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{uri: "file:///text.txt", want: `{"contents":[{"uri":"file:///text.txt","mimeType":"text/plain","text":"hello"}]}`},
+		{uri: "file:///blob.bin", want: `{"contents":[{"uri":"file:///blob.bin","mimeType":"application/octet-stream","blob":"AAEC"}]}`},
+		{uri: "file:///func.txt", want: `{"contents":[{"uri":"file:///func.txt","text":"from func"}]}`},
+		{uri: "file:///reader.md", want: `{"contents":[{"uri":"file:///reader.md","mimeType":"text/markdown","text":"# title"}]}`},
+		// the reader is read only once, and the contents are reused
+		{uri: "file:///reader.md", want: `{"contents":[{"uri":"file:///reader.md","mimeType":"text/markdown","text":"# title"}]}`},
+	}
+
+	for _, tt := range tests {
+		result, err := server.ReadResource(context.Background(), &Request[ReadResourceRequestParams]{
+			Params: ReadResourceRequestParams{URI: tt.uri},
+		})
+		if err != nil {
+			t.Fatalf("ReadResource(%s) error = %v", tt.uri, err)
+		}
+		got, err := json.Marshal(result)
+		if err != nil {
+			t.Fatalf("failed to marshal result: %v", err)
+		}
+		assertJSONEqual(t, tt.want, string(got))
+	}
+
+	list, err := server.ListResources(context.Background(), &Request[ListResourcesRequestParams]{})
+	if err != nil {
+		t.Fatalf("ListResources() error = %v", err)
+	}
+	if len(list.Data.Resources) != 4 {
+		t.Errorf("ListResources() returned %d resources; want 4", len(list.Data.Resources))
+	}
+
+	_, err = server.ReadResource(context.Background(), &Request[ReadResourceRequestParams]{
+		Params: ReadResourceRequestParams{URI: "file:///unknown.txt"},
+	})
+	if !errors.Is(err, ErrResourceNotFound) {
+		t.Errorf("expected ErrResourceNotFound for unknown URI, got %v", err)
+	}
+}
+
+func TestServer_ReadResourceWithoutReader(t *testing.T) {
+	server := mustNewServer(t, "test", "1.0.0")
+
+	_, err := server.ReadResource(context.Background(), &Request[ReadResourceRequestParams]{
+		Params: ReadResourceRequestParams{URI: "file:///unknown.txt"},
+	})
+	if !errors.Is(err, ErrResourceNotFound) {
+		t.Errorf("expected ErrResourceNotFound, got %v", err)
+	}
+}
+
+type staticResourceReader struct{}
+
+func (staticResourceReader) ReadResource(ctx context.Context, request *Request[ReadResourceRequestParams]) (*Result[ReadResourceResultData], error) {
+	return &Result[ReadResourceResultData]{}, nil
+}
+
+func TestWithResourceContents_Errors(t *testing.T) {
+	_, err := NewServer("test", "1.0.0",
+		WithResourceReader(staticResourceReader{}),
+		WithResourceContents(Resource{URI: "file:///a.txt"}, TextContents("a")),
+	)
+	if err == nil {
+		t.Error("expected error for a resource reader other than *ResourceReaderMux")
+	}
+
+	_, err = NewServer("test", "1.0.0",
+		WithResourceContents(Resource{URI: "file:///a.txt"}, TextContents("a")),
+		WithResourceContents(Resource{URI: "file:///a.txt"}, TextContents("b")),
+	)
+	if err == nil {
+		t.Error("expected error for duplicated resource URI")
+	}
+}
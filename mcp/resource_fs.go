@@ -0,0 +1,302 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// sniffLen is the number of bytes used to detect the MIME type of a file, as http.DetectContentType does.
+const sniffLen = 512
+
+// FSResources is a ResourceProvider that provides the files in an fs.FS as resources.
+// The URI of a file is the base URI joined with the slash-separated path of the file, e.g.
+// "file:///srv/data/docs/readme.md" for the file "docs/readme.md" with the base URI "file:///srv/data".
+//
+// Only paths inside fsys are read, as checked by fs.ValidPath.
+// Note that fs.FS implementations such as os.DirFS may follow symbolic links out of the directory;
+// use (*os.Root).FS to prevent it.
+type FSResources struct {
+	fsys    fs.FS
+	baseURI string
+	logger  *slog.Logger
+
+	mu       sync.Mutex
+	snapshot map[string]fileState
+}
+
+// fileState is the state of a file used to detect changes.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// FSResourcesOption is a function that configures a FSResources.
+type FSResourcesOption func(*FSResources)
+
+// WithFSResourcesLogger sets a logger for the errors found while watching the files.
+func WithFSResourcesLogger(logger *slog.Logger) FSResourcesOption {
+	return func(r *FSResources) {
+		r.logger = logger
+	}
+}
+
+// NewFSResources creates a new FSResources for the files in fsys.
+// baseURI is the URI of the root of fsys, e.g. "file:///srv/data" or "docs://".
+func NewFSResources(fsys fs.FS, baseURI string, opts ...FSResourcesOption) *FSResources {
+	r := &FSResources{
+		fsys:    fsys,
+		baseURI: baseURI,
+		logger:  slog.New(slog.DiscardHandler),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Resources implements ResourceProvider.
+// Resources lists the regular files in fsys in lexical order.
+func (r *FSResources) Resources(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+	err := fs.WalkDir(r.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		mimeType, err := r.mimeType(name, nil)
+		if err != nil {
+			return err
+		}
+
+		resources = append(resources, Resource{
+			URI:      r.uri(name),
+			Name:     path.Base(name),
+			MimeType: mimeType,
+			Size:     info.Size(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resources, nil
+}
+
+// ResourceContents implements ResourceProvider.
+// The contents are TextResourceContents if the file is valid UTF-8 text, otherwise BlobResourceContents.
+// ResourceContents returns a *ResourceNotFoundError if the URI is not a regular file in fsys.
+func (r *FSResources) ResourceContents(ctx context.Context, uri string) ([]IsResourceContents, error) {
+	name, ok := r.name(uri)
+	if !ok {
+		return nil, &ResourceNotFoundError{URI: uri}
+	}
+
+	info, err := fs.Stat(r.fsys, name)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && !info.Mode().IsRegular()) {
+		return nil, &ResourceNotFoundError{URI: uri}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := fs.ReadFile(r.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	mimeType, err := r.mimeType(name, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if isText(data) {
+		return []IsResourceContents{
+			&TextResourceContents{URI: uri, MimeType: mimeType, Text: string(data)},
+		}, nil
+	}
+	return []IsResourceContents{
+		&BlobResourceContents{URI: uri, MimeType: mimeType, Blob: data},
+	}, nil
+}
+
+// Watch polls fsys at the interval and calls onChange for each created, modified or removed file,
+// e.g. Server.NotifyResourceChange.
+// The first poll records the current files without reporting them.
+// The errors of onChange are logged by the logger set by WithFSResourcesLogger, and don't stop watching.
+// Watch blocks until ctx is done, and returns the context error.
+// Watch returns an error at once if interval isn't positive.
+func (r *FSResources) Watch(ctx context.Context, interval time.Duration, onChange func(ctx context.Context, change ResourceChange) error) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid watch interval: %v", interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	if _, err := r.poll(); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		changes, err := r.poll()
+		if err != nil {
+			// The files may be in the middle of being changed; try again at the next tick.
+			r.logger.DebugContext(ctx, "failed to poll files", "error", err)
+			continue
+		}
+		for _, change := range changes {
+			if err := onChange(ctx, change); err != nil {
+				r.logger.ErrorContext(ctx, "failed to handle resource change", "uri", change.URI, "kind", change.Kind, "error", err)
+			}
+		}
+	}
+}
+
+// poll takes a snapshot of the files and returns the changes since the previous snapshot.
+// poll returns no changes for the first snapshot.
+func (r *FSResources) poll() ([]ResourceChange, error) {
+	snapshot := make(map[string]fileState)
+	err := fs.WalkDir(r.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		snapshot[name] = fileState{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.snapshot
+	r.snapshot = snapshot
+	if previous == nil {
+		return nil, nil
+	}
+
+	var changes []ResourceChange
+	for name, state := range snapshot {
+		old, ok := previous[name]
+		switch {
+		case !ok:
+			changes = append(changes, ResourceChange{URI: r.uri(name), Kind: ResourceCreated})
+		case old.size != state.size || !old.modTime.Equal(state.modTime):
+			changes = append(changes, ResourceChange{URI: r.uri(name), Kind: ResourceModified})
+		}
+	}
+	for name := range previous {
+		if _, ok := snapshot[name]; !ok {
+			changes = append(changes, ResourceChange{URI: r.uri(name), Kind: ResourceRemoved})
+		}
+	}
+	slices.SortFunc(changes, func(a, b ResourceChange) int {
+		return strings.Compare(a.URI, b.URI)
+	})
+
+	return changes, nil
+}
+
+// uri returns the URI of the file.
+func (r *FSResources) uri(name string) string {
+	segments := strings.Split(name, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	escaped := strings.Join(segments, "/")
+
+	if strings.HasSuffix(r.baseURI, "://") {
+		return r.baseURI + escaped
+	}
+	return strings.TrimSuffix(r.baseURI, "/") + "/" + escaped
+}
+
+// name returns the name of the file in fsys for the URI.
+// name returns false if the URI is not under the base URI or the path escapes fsys.
+func (r *FSResources) name(uri string) (string, bool) {
+	base := r.baseURI
+	if !strings.HasSuffix(base, "://") {
+		base = strings.TrimSuffix(base, "/") + "/"
+	}
+
+	escaped, ok := strings.CutPrefix(uri, base)
+	if !ok {
+		return "", false
+	}
+	name, err := url.PathUnescape(escaped)
+	if err != nil {
+		return "", false
+	}
+	if !fs.ValidPath(name) || name == "." {
+		return "", false
+	}
+	return name, true
+}
+
+// mimeType returns the MIME type of the file.
+// The MIME type is determined by the extension, or by the content if the extension is unknown.
+// data is the content of the file, or nil to read it from fsys if needed.
+func (r *FSResources) mimeType(name string, data []byte) (string, error) {
+	if mimeType := mime.TypeByExtension(path.Ext(name)); mimeType != "" {
+		return mimeType, nil
+	}
+
+	if data == nil {
+		f, err := r.fsys.Open(name)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+
+		buf := make([]byte, sniffLen)
+		n, err := io.ReadFull(f, buf)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return "", err
+		}
+		data = buf[:n]
+	}
+
+	return http.DetectContentType(data), nil
+}
+
+// isText reports whether data is text, that is, valid UTF-8 without NUL bytes.
+func isText(data []byte) bool {
+	return utf8.Valid(data) && !slices.Contains(data, 0)
+}
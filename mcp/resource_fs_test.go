@@ -0,0 +1,259 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"reflect"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func newTestFS() fstest.MapFS {
+	modTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	return fstest.MapFS{
+		"config.json":        {Data: []byte(`{"a":1}`), ModTime: modTime},
+		"docs/hello world":   {Data: []byte("hello"), ModTime: modTime},
+		"images/logo.png":    {Data: []byte("\x89PNG\r\n\x1a\n\x00"), ModTime: modTime},
+		"data/unknown":       {Data: []byte{0x00, 0x01, 0x02}, ModTime: modTime},
+		"docs/empty/.keep":   {Data: nil, ModTime: modTime},
+		"docs/empty/sub/dir": {Mode: fs.ModeDir | 0o755, ModTime: modTime},
+	}
+}
+
+func TestFSResources_Resources(t *testing.T) {
+	r := NewFSResources(newTestFS(), "file:///srv")
+
+	resources, err := r.Resources(context.Background())
+	if err != nil {
+		t.Fatalf("Resources() error = %v", err)
+	}
+
+	want := []Resource{
+		{URI: "file:///srv/config.json", Name: "config.json", MimeType: "application/json", Size: 7},
+		{URI: "file:///srv/data/unknown", Name: "unknown", MimeType: "application/octet-stream", Size: 3},
+		{URI: "file:///srv/docs/empty/.keep", Name: ".keep", MimeType: "text/plain; charset=utf-8", Size: 0},
+		{URI: "file:///srv/docs/hello%20world", Name: "hello world", MimeType: "text/plain; charset=utf-8", Size: 5},
+		{URI: "file:///srv/images/logo.png", Name: "logo.png", MimeType: "image/png", Size: 9},
+	}
+	if !reflect.DeepEqual(resources, want) {
+		t.Errorf("Resources() = %+v; want %+v", resources, want)
+	}
+}
+
+func TestFSResources_ResourceContents(t *testing.T) {
+	r := NewFSResources(newTestFS(), "docs://")
+
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{uri: "docs://config.json", want: `[{"uri":"docs://config.json","mimeType":"application/json","text":"{\"a\":1}"}]`},
+		{uri: "docs://docs/hello%20world", want: `[{"uri":"docs://docs/hello%20world","mimeType":"text/plain; charset=utf-8","text":"hello"}]`},
+		{uri: "docs://data/unknown", want: `[{"uri":"docs://data/unknown","mimeType":"application/octet-stream","blob":"AAEC"}]`},
+	}
+	for _, tt := range tests {
+		contents, err := r.ResourceContents(context.Background(), tt.uri)
+		if err != nil {
+			t.Fatalf("ResourceContents(%s) error = %v", tt.uri, err)
+		}
+		got, err := json.Marshal(contents)
+		if err != nil {
+			t.Fatalf("failed to marshal contents: %v", err)
+		}
+		assertJSONEqual(t, tt.want, string(got))
+	}
+
+	for _, uri := range []string{
+		"docs://missing.txt",
+		"docs://docs",
+		"docs://../etc/passwd",
+		"docs://%2E%2E/etc/passwd",
+		"docs:///config.json",
+		"other://config.json",
+	} {
+		if _, err := r.ResourceContents(context.Background(), uri); !errors.Is(err, ErrResourceNotFound) {
+			t.Errorf("ResourceContents(%s) error = %v; want ErrResourceNotFound", uri, err)
+		}
+	}
+}
+
+func TestFSResources_poll(t *testing.T) {
+	fsys := newTestFS()
+	r := NewFSResources(fsys, "file:///srv")
+
+	changes, err := r.poll()
+	if err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("first poll() = %+v; want no changes", changes)
+	}
+
+	fsys["config.json"] = &fstest.MapFile{Data: []byte(`{"a":2}`), ModTime: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)}
+	fsys["new.txt"] = &fstest.MapFile{Data: []byte("new")}
+	delete(fsys, "images/logo.png")
+
+	changes, err = r.poll()
+	if err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+	want := []ResourceChange{
+		{URI: "file:///srv/config.json", Kind: ResourceModified},
+		{URI: "file:///srv/images/logo.png", Kind: ResourceRemoved},
+		{URI: "file:///srv/new.txt", Kind: ResourceCreated},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("poll() = %+v; want %+v", changes, want)
+	}
+}
+
+// lockedFS guards a fstest.MapFS changed by a test while it is read by FSResources.Watch.
+type lockedFS struct {
+	mu   sync.Mutex
+	fsys fstest.MapFS
+}
+
+func (l *lockedFS) Open(name string) (fs.File, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.fsys.Open(name)
+}
+
+func (l *lockedFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.fsys.ReadDir(name)
+}
+
+func (l *lockedFS) Stat(name string) (fs.FileInfo, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.fsys.Stat(name)
+}
+
+// change changes the files; the *fstest.MapFile values are replaced instead of modified.
+func (l *lockedFS) change(f func(fsys fstest.MapFS)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f(l.fsys)
+}
+
+func TestFSResources_Watch(t *testing.T) {
+	// Server.NotifyResourceChange can be used as the callback of Watch
+	var _ func(context.Context, ResourceChange) error = (&Server{}).NotifyResourceChange
+
+	fsys := &lockedFS{fsys: newTestFS()}
+	r := NewFSResources(fsys, "file:///srv")
+
+	changes := make(chan ResourceChange, 10)
+	ctx, cancel := context.WithCancel(t.Context())
+	watched := make(chan error, 1)
+	go func() {
+		watched <- r.Watch(ctx, 10*time.Millisecond, func(ctx context.Context, change ResourceChange) error {
+			changes <- change
+			// An error of the callback doesn't stop watching
+			return errors.New("failed to notify")
+		})
+	}()
+
+	// Wait for the first poll recording the current files
+	for {
+		r.mu.Lock()
+		polled := r.snapshot != nil
+		r.mu.Unlock()
+		if polled {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	next := func() ResourceChange {
+		t.Helper()
+		select {
+		case change := <-changes:
+			return change
+		case <-time.After(time.Second):
+			t.Fatal("change not reported")
+			return ResourceChange{}
+		}
+	}
+
+	fsys.change(func(fsys fstest.MapFS) {
+		fsys["new.txt"] = &fstest.MapFile{Data: []byte("new")}
+	})
+	if got, want := next(), (ResourceChange{URI: "file:///srv/new.txt", Kind: ResourceCreated}); got != want {
+		t.Errorf("change = %+v; want %+v", got, want)
+	}
+
+	fsys.change(func(fsys fstest.MapFS) {
+		fsys["config.json"] = &fstest.MapFile{Data: []byte(`{"a":2}`), ModTime: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)}
+	})
+	if got, want := next(), (ResourceChange{URI: "file:///srv/config.json", Kind: ResourceModified}); got != want {
+		t.Errorf("change = %+v; want %+v", got, want)
+	}
+
+	fsys.change(func(fsys fstest.MapFS) {
+		delete(fsys, "images/logo.png")
+	})
+	if got, want := next(), (ResourceChange{URI: "file:///srv/images/logo.png", Kind: ResourceRemoved}); got != want {
+		t.Errorf("change = %+v; want %+v", got, want)
+	}
+
+	cancel()
+	if err := <-watched; !errors.Is(err, context.Canceled) {
+		t.Errorf("Watch() error = %v; want context.Canceled", err)
+	}
+	select {
+	case change := <-changes:
+		t.Errorf("unexpected change %+v", change)
+	default:
+	}
+}
+
+func TestFSResources_WatchInvalidInterval(t *testing.T) {
+	r := NewFSResources(newTestFS(), "file:///srv")
+	for _, interval := range []time.Duration{0, -time.Second} {
+		err := r.Watch(t.Context(), interval, func(ctx context.Context, change ResourceChange) error {
+			return nil
+		})
+		if err == nil {
+			t.Errorf("Watch(%v) error = nil, want error", interval)
+		}
+	}
+}
+
+func TestWithResourceProvider(t *testing.T) {
+	server := mustNewServer(t, "test", "1.0.0",
+		WithResource(Resource{URI: "test://static", Name: "static"}),
+		WithResourceProvider(NewFSResources(newTestFS(), "file:///srv")),
+	)
+
+	list, err := server.ListResources(context.Background(), &Request[ListResourcesRequestParams]{})
+	if err != nil {
+		t.Fatalf("ListResources() error = %v", err)
+	}
+	if len(list.Data.Resources) != 6 || list.Data.Resources[0].URI != "test://static" {
+		t.Errorf("ListResources() = %+v; want the static resource followed by 5 files", list.Data.Resources)
+	}
+
+	result, err := server.ReadResource(context.Background(), &Request[ReadResourceRequestParams]{
+		Params: ReadResourceRequestParams{URI: "file:///srv/config.json"},
+	})
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	if len(result.Data.Contents) != 1 {
+		t.Errorf("ReadResource() returned %d contents; want 1", len(result.Data.Contents))
+	}
+
+	_, err = server.ReadResource(context.Background(), &Request[ReadResourceRequestParams]{
+		Params: ReadResourceRequestParams{URI: "file:///srv/missing"},
+	})
+	if !errors.Is(err, ErrResourceNotFound) {
+		t.Errorf("expected ErrResourceNotFound, got %v", err)
+	}
+}
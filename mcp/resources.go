@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
 
 	"github.com/Warashi/go-modelcontextprotocol/router"
 )
@@ -118,21 +122,151 @@ type ResourceReader interface {
 	ReadResource(ctx context.Context, request *Request[ReadResourceRequestParams]) (*Result[ReadResourceResultData], error)
 }
 
+// ResourceProvider provides a set of resources that may change over time, e.g. the files in a directory.
+type ResourceProvider interface {
+	// Resources lists the resources.
+	Resources(ctx context.Context) ([]Resource, error)
+	// ResourceContents reads the contents of the resource.
+	// ResourceContents returns a *ResourceNotFoundError if the provider does not have the resource.
+	ResourceContents(ctx context.Context, uri string) ([]IsResourceContents, error)
+}
+
 // ResourceReaderMux is a multiplexer for resource readers.
+// It is safe to register resources, providers and routes while serving.
 type ResourceReaderMux struct {
 	mux *router.Mux[*Result[ReadResourceResultData]]
+
+	// mu guards resources, providers and templates
+	mu sync.RWMutex
+	// resources holds the resources registered with HandleResource, keyed by URI.
+	resources map[string]registeredResource
+	// providers holds the providers registered with HandleProvider.
+	providers []ResourceProvider
+	// templates holds the templates registered with HandleTemplate, keyed by URI template.
+	templates map[string]ResourceTemplate
+}
+
+// registeredResource is a resource registered with its contents provider.
+type registeredResource struct {
+	resource Resource
+	provider ResourceContentsProvider
 }
 
 // NewResourceReaderMux creates a new resource reader multiplexer.
 func NewResourceReaderMux() *ResourceReaderMux {
 	return &ResourceReaderMux{
-		mux: router.NewMux[*Result[ReadResourceResultData]](),
+		mux:       router.NewMux[*Result[ReadResourceResultData]](),
+		resources: make(map[string]registeredResource),
+		templates: make(map[string]ResourceTemplate),
 	}
 }
 
 // ReadResource reads a resource.
+// Resources registered with HandleResource take precedence over the providers registered with HandleProvider,
+// which in turn take precedence over the routes.
+// ReadResource returns a *ResourceNotFoundError if no route matches the URI and no not found handler is set,
+// or if the URI cannot be routed.
 func (m *ResourceReaderMux) ReadResource(ctx context.Context, request *Request[ReadResourceRequestParams]) (*Result[ReadResourceResultData], error) {
-	return m.mux.Execute(ctx, request.Params.URI)
+	// The lock is not held while reading, so that the providers may register resources
+	m.mu.RLock()
+	r, ok := m.resources[request.Params.URI]
+	providers := m.providers
+	m.mu.RUnlock()
+
+	if ok {
+		contents, err := r.provider.ResourceContents(ctx, r.resource)
+		if err != nil {
+			return nil, err
+		}
+		return &Result[ReadResourceResultData]{
+			Data: ReadResourceResultData{
+				Contents: contents,
+			},
+		}, nil
+	}
+
+	for _, p := range providers {
+		contents, err := p.ResourceContents(ctx, request.Params.URI)
+		if errors.Is(err, ErrResourceNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &Result[ReadResourceResultData]{
+			Data: ReadResourceResultData{
+				Contents: contents,
+			},
+		}, nil
+	}
+
+	result, err := m.mux.Execute(ctx, request.Params.URI)
+	if errors.Is(err, router.ErrNotFound) || errors.Is(err, router.ErrInvalidURI) {
+		return nil, &ResourceNotFoundError{URI: request.Params.URI}
+	}
+	return result, err
+}
+
+// HandleResource registers a resource with the provider of its contents.
+// The resource is read by its exact URI.
+// HandleResource returns an error if the URI is empty or already registered.
+func (m *ResourceReaderMux) HandleResource(resource Resource, provider ResourceContentsProvider) error {
+	if resource.URI == "" {
+		return errors.New("resource URI is required")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.resources[resource.URI]; ok {
+		return fmt.Errorf("resource already registered: %s", resource.URI)
+	}
+
+	m.resources[resource.URI] = registeredResource{resource: resource, provider: provider}
+	return nil
+}
+
+// HandleProvider registers a provider of resources.
+// Providers are tried in the order of registration.
+func (m *ResourceReaderMux) HandleProvider(provider ResourceProvider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.providers = append(m.providers, provider)
+}
+
+// HandleTemplate registers a new route for the URI template of the resource template with a handler.
+// The resource template is listed by ResourceTemplates, so that it is always in sync with the route.
+func (m *ResourceReaderMux) HandleTemplate(template ResourceTemplate, h router.Handler[*Result[ReadResourceResultData]]) error {
+	// The lock is held while registering the route, so that ResourceTemplates sees the route and the template together
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.mux.Handle(template.URITemplate, h); err != nil {
+		return err
+	}
+	m.templates[template.URITemplate] = template
+	return nil
+}
+
+// HandleTemplateFunc registers a new route for the URI template of the resource template with a handler function.
+func (m *ResourceReaderMux) HandleTemplateFunc(template ResourceTemplate, f func(context.Context, *router.Request) (*Result[ReadResourceResultData], error)) error {
+	return m.HandleTemplate(template, router.HandlerFunc[*Result[ReadResourceResultData]](f))
+}
+
+// ResourceTemplates returns the resource templates registered with HandleTemplate in the order of registration.
+// Routes registered with Handle or HandleFunc are not listed.
+func (m *ResourceReaderMux) ResourceTemplates() []ResourceTemplate {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var templates []ResourceTemplate
+	for _, pattern := range m.mux.Patterns() {
+		if template, ok := m.templates[pattern]; ok {
+			templates = append(templates, template)
+		}
+	}
+	return templates
 }
 
 // Handle registers a new route with a handler.
@@ -156,24 +290,51 @@ func (m *ResourceReaderMux) SetNotFoundHandlerFunc(f func(context.Context, *rout
 }
 
 // ListResources lists resources.
+// The resources of the providers set by WithResourceProvider follow the resources set by WithResource.
 func (s *Server) ListResources(ctx context.Context, request *Request[ListResourcesRequestParams]) (*Result[ListResourcesResultData], error) {
+	resources := s.resources
+	if len(s.resourceProviders) > 0 {
+		resources = slices.Clone(s.resources)
+		for _, p := range s.resourceProviders {
+			provided, err := p.Resources(ctx)
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, provided...)
+		}
+	}
+
 	return &Result[ListResourcesResultData]{
 		Data: ListResourcesResultData{
-			Resources: s.resources,
+			Resources: resources,
 		},
 	}, nil
 }
 
 // ListResourceTemplates lists resource templates.
+// The templates registered to the resource reader by ResourceReaderMux.HandleTemplate follow the templates set by WithResourceTemplate.
 func (s *Server) ListResourceTemplates(ctx context.Context, request *Request[ListResourceTemplatesRequestParams]) (*Result[ListResourceTemplatesResultData], error) {
 	return &Result[ListResourceTemplatesResultData]{
 		Data: ListResourceTemplatesResultData{
-			ResourceTemplates: s.resourceTemplates,
+			ResourceTemplates: s.listResourceTemplates(),
 		},
 	}, nil
 }
 
+// listResourceTemplates returns the resource templates set by WithResourceTemplate and registered to the resource reader.
+func (s *Server) listResourceTemplates() []ResourceTemplate {
+	mux, ok := s.resourceReader.(*ResourceReaderMux)
+	if !ok {
+		return s.resourceTemplates
+	}
+	return append(slices.Clone(s.resourceTemplates), mux.ResourceTemplates()...)
+}
+
 // ReadResource reads a resource.
+// ReadResource returns a *ResourceNotFoundError if no resource reader is set.
 func (s *Server) ReadResource(ctx context.Context, request *Request[ReadResourceRequestParams]) (*Result[ReadResourceResultData], error) {
+	if s.resourceReader == nil {
+		return nil, &ResourceNotFoundError{URI: request.Params.URI}
+	}
 	return s.resourceReader.ReadResource(ctx, request)
 }
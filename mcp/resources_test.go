@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/Warashi/go-modelcontextprotocol/router"
@@ -367,3 +369,148 @@ func generateLargeByteSlice(size int) []byte {
 	}
 	return data
 }
+
+func TestResourceReaderMux_HandleTemplate(t *testing.T) {
+	mux := NewResourceReaderMux()
+	template := ResourceTemplate{
+		URITemplate: "example://resource/{id}",
+		Name:        "Example",
+		Description: "An example resource",
+		MimeType:    "text/plain",
+	}
+	err := mux.HandleTemplateFunc(template, func(ctx context.Context, req *router.Request) (*Result[ReadResourceResultData], error) {
+		return &Result[ReadResourceResultData]{
+			Data: ReadResourceResultData{
+				Contents: []IsResourceContents{
+					&TextResourceContents{URI: "example://resource/" + req.Params["id"], Text: req.Params["id"]},
+				},
+			},
+		}, nil
+	})
+	if err != nil {
+		t.Fatalf("HandleTemplateFunc() error = %v", err)
+	}
+	// routes without metadata are not listed
+	if err := mux.HandleFunc("example://other/{id}", func(ctx context.Context, req *router.Request) (*Result[ReadResourceResultData], error) {
+		return nil, nil
+	}); err != nil {
+		t.Fatalf("HandleFunc() error = %v", err)
+	}
+
+	server := mustNewServer(t, "test", "1.0.0",
+		WithResourceTemplate(ResourceTemplate{URITemplate: "static://{name}", Name: "Static"}),
+		WithResourceReader(mux),
+	)
+
+	result, err := server.ListResourceTemplates(context.Background(), &Request[ListResourceTemplatesRequestParams]{})
+	if err != nil {
+		t.Fatalf("ListResourceTemplates() error = %v", err)
+	}
+	want := []ResourceTemplate{
+		{URITemplate: "static://{name}", Name: "Static"},
+		template,
+	}
+	if !reflect.DeepEqual(result.Data.ResourceTemplates, want) {
+		t.Errorf("ListResourceTemplates() = %+v; want %+v", result.Data.ResourceTemplates, want)
+	}
+
+	read, err := server.ReadResource(context.Background(), &Request[ReadResourceRequestParams]{
+		Params: ReadResourceRequestParams{URI: "example://resource/42"},
+	})
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	if got := read.Data.Contents[0].(*TextResourceContents).Text; got != "42" {
+		t.Errorf("ReadResource() text = %q; want %q", got, "42")
+	}
+}
+
+func TestResourceReaderMux_FileTemplate(t *testing.T) {
+	mux := NewResourceReaderMux()
+	err := mux.HandleTemplateFunc(ResourceTemplate{URITemplate: "file:///{path...}", Name: "Files"}, func(ctx context.Context, req *router.Request) (*Result[ReadResourceResultData], error) {
+		return &Result[ReadResourceResultData]{
+			Data: ReadResourceResultData{
+				Contents: []IsResourceContents{
+					&TextResourceContents{URI: "file:///" + req.Params["path"], Text: req.Params["path"]},
+				},
+			},
+		}, nil
+	})
+	if err != nil {
+		t.Fatalf("HandleTemplateFunc() error = %v", err)
+	}
+
+	read, err := mux.ReadResource(context.Background(), &Request[ReadResourceRequestParams]{
+		Params: ReadResourceRequestParams{URI: "file:///docs/readme.md"},
+	})
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	if got := read.Data.Contents[0].(*TextResourceContents).Text; got != "docs/readme.md" {
+		t.Errorf("ReadResource() text = %q; want %q", got, "docs/readme.md")
+	}
+}
+
+// TestResourceReaderMux_RegisterWhileServing registers resources and templates while they are read and listed.
+// It is meaningful with the race detector.
+func TestResourceReaderMux_RegisterWhileServing(t *testing.T) {
+	mux := NewResourceReaderMux()
+	handler := func(ctx context.Context, req *router.Request) (*Result[ReadResourceResultData], error) {
+		return &Result[ReadResourceResultData]{}, nil
+	}
+
+	const n = 50
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := range n {
+			uri := fmt.Sprintf("example://static/%d", i)
+			if err := mux.HandleResource(Resource{URI: uri, Name: uri}, TextContents("text")); err != nil {
+				t.Errorf("HandleResource() error = %v", err)
+			}
+			mux.HandleProvider(NewFSResources(newTestFS(), "file:///srv"))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := range n {
+			template := ResourceTemplate{URITemplate: fmt.Sprintf("example://template%d/{id}", i), Name: "Example"}
+			if err := mux.HandleTemplateFunc(template, handler); err != nil {
+				t.Errorf("HandleTemplateFunc() error = %v", err)
+			}
+		}
+	}()
+	done := make(chan struct{})
+	reading := make(chan struct{})
+	go func() {
+		defer close(reading)
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			mux.ReadResource(context.Background(), &Request[ReadResourceRequestParams]{
+				Params: ReadResourceRequestParams{URI: fmt.Sprintf("example://static/%d", i%n)},
+			})
+			mux.ResourceTemplates()
+		}
+	}()
+	wg.Wait()
+	close(done)
+	<-reading
+
+	if got := len(mux.ResourceTemplates()); got != n {
+		t.Errorf("ResourceTemplates() = %d templates; want %d", got, n)
+	}
+	read, err := mux.ReadResource(context.Background(), &Request[ReadResourceRequestParams]{
+		Params: ReadResourceRequestParams{URI: "example://static/0"},
+	})
+	if err != nil {
+		t.Fatalf("ReadResource() error = %v", err)
+	}
+	if got := read.Data.Contents[0].(*TextResourceContents).Text; got != "text" {
+		t.Errorf("ReadResource() text = %q; want %q", got, "text")
+	}
+}
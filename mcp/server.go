@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"sync"
@@ -44,6 +45,25 @@ func WithResource(resource Resource) ServerOption {
 	}
 }
 
+// WithResourceContents sets a resource for the server, together with the provider of its contents.
+// The resource is registered to the resource reader, which must be a *ResourceReaderMux if set by WithResourceReader.
+// If no resource reader is set, a new ResourceReaderMux is used.
+func WithResourceContents(resource Resource, provider ResourceContentsProvider) ServerOption {
+	return func(s *Server) {
+		s.resources = append(s.resources, resource)
+		s.resourceContents = append(s.resourceContents, registeredResource{resource: resource, provider: provider})
+	}
+}
+
+// WithResourceProvider sets a provider of resources for the server, e.g. FSResources.
+// The provider is registered to the resource reader, which must be a *ResourceReaderMux if set by WithResourceReader.
+// If no resource reader is set, a new ResourceReaderMux is used.
+func WithResourceProvider(provider ResourceProvider) ServerOption {
+	return func(s *Server) {
+		s.resourceProviders = append(s.resourceProviders, provider)
+	}
+}
+
 // WithResourceTemplate sets a resource template for the server.
 func WithResourceTemplate(template ResourceTemplate) ServerOption {
 	return func(s *Server) {
@@ -58,6 +78,14 @@ func WithResourceReader(reader ResourceReader) ServerOption {
 	}
 }
 
+// WithConnectionOptions sets options for the JSON-RPC 2.0 connections of the server.
+// You can use this to configure timeouts, for example.
+func WithConnectionOptions(opts ...jsonrpc2.ConnectionInitializationOption) ServerOption {
+	return func(s *Server) {
+		s.initOpts = append(s.initOpts, opts...)
+	}
+}
+
 // WithLogger sets a logger for the server.
 func WithLogger(logger *slog.Logger) ServerOption {
 	return func(s *Server) {
@@ -77,10 +105,24 @@ type Server struct {
 	resources         []Resource
 	resourceTemplates []ResourceTemplate
 	resourceReader    ResourceReader
+	resourceContents  []registeredResource
+	resourceProviders []ResourceProvider
 
 	mu          sync.Mutex
-	connections map[uint64]*jsonrpc2.Conn
-	logger      *slog.Logger
+	connections map[uint64]*session
+	// shuttingDown is set by Shutdown, after which Serve refuses new sessions
+	shuttingDown bool
+	logger       *slog.Logger
+}
+
+// ErrServerShuttingDown is returned by Serve for a session started after Shutdown is called.
+var ErrServerShuttingDown = errors.New("server is shutting down")
+
+// session is the state of a connection of the server.
+type session struct {
+	conn *jsonrpc2.Conn
+	// subscriptions is the set of the URIs of the resources subscribed by the client, guarded by Server.mu
+	subscriptions map[string]struct{}
 }
 
 // NewServer creates a new MCP server.
@@ -91,7 +133,7 @@ func NewServer(name, version string, opts ...ServerOption) (*Server, error) {
 		tools:             make(map[string]tool),
 		resources:         make([]Resource, 0),         // to return empty list instead of nil
 		resourceTemplates: make([]ResourceTemplate, 0), // to return empty list instead of nil
-		connections:       make(map[uint64]*jsonrpc2.Conn),
+		connections:       make(map[uint64]*session),
 		logger:            slog.New(slog.DiscardHandler),
 	}
 
@@ -99,6 +141,10 @@ func NewServer(name, version string, opts ...ServerOption) (*Server, error) {
 		opt(s)
 	}
 
+	if err := s.registerResourceContents(); err != nil {
+		return nil, err
+	}
+
 	var initOpts []jsonrpc2.ConnectionInitializationOption
 	initOpts = append(initOpts,
 		jsonrpc2.WithHandlerFunc("ping", s.Ping),
@@ -110,6 +156,7 @@ func NewServer(name, version string, opts ...ServerOption) (*Server, error) {
 		jsonrpc2.WithHandlerFunc("resources/read", s.ReadResource),
 		jsonrpc2.WithHandlerFunc("resources/templates/list", s.ListResourceTemplates),
 		jsonrpc2.WithLogger(s.logger),
+		jsonrpc2.WithCancelNotifier(NotifyCancelled),
 	)
 
 	// append custom init opts after default handlers
@@ -121,6 +168,31 @@ func NewServer(name, version string, opts ...ServerOption) (*Server, error) {
 	return s, nil
 }
 
+// registerResourceContents registers the resources set by WithResourceContents and WithResourceProvider to the resource reader.
+func (s *Server) registerResourceContents() error {
+	if len(s.resourceContents) == 0 && len(s.resourceProviders) == 0 {
+		return nil
+	}
+
+	if s.resourceReader == nil {
+		s.resourceReader = NewResourceReaderMux()
+	}
+	mux, ok := s.resourceReader.(*ResourceReaderMux)
+	if !ok {
+		return fmt.Errorf("resource reader must be a *ResourceReaderMux to register resource contents, got %T", s.resourceReader)
+	}
+
+	for _, r := range s.resourceContents {
+		if err := mux.HandleResource(r.resource, r.provider); err != nil {
+			return err
+		}
+	}
+	for _, p := range s.resourceProviders {
+		mux.HandleProvider(p)
+	}
+	return nil
+}
+
 // SSEHandler returns a handler for the SSE transport.
 func (s *Server) SSEHandler(baseURL string) (http.Handler, error) {
 	return transport.NewSSE(baseURL, s)
@@ -137,14 +209,62 @@ func (s *Server) ServeStdio(ctx context.Context) error {
 }
 
 // Serve starts the server.
+// Serve returns ErrServerShuttingDown without serving the session if Shutdown has been called.
 func (s *Server) Serve(ctx context.Context, id uint64, t transport.Session) error {
-	conn := jsonrpc2.NewConnection(t, s.initOpts...)
+	sess := &session{subscriptions: make(map[string]struct{})}
+	// The handlers of the session come first, so that they can be overridden by WithCustomHandler as well
+	opts := append([]jsonrpc2.ConnectionInitializationOption{
+		jsonrpc2.WithHandlerFunc("resources/subscribe", s.subscribeResource(sess)),
+		jsonrpc2.WithHandlerFunc("resources/unsubscribe", s.unsubscribeResource(sess)),
+	}, s.initOpts...)
+	sess.conn = jsonrpc2.NewConnection(t, opts...)
 
 	s.mu.Lock()
-	s.connections[id] = conn
+	if s.shuttingDown {
+		s.mu.Unlock()
+		return ErrServerShuttingDown
+	}
+	s.connections[id] = sess
 	s.mu.Unlock()
 
-	return conn.Serve(ctx)
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		// The id may have been reused by another connection in the meantime.
+		if s.connections[id] == sess {
+			delete(s.connections, id)
+		}
+	}()
+
+	return sess.conn.Serve(ctx)
+}
+
+// Shutdown gracefully shuts down all connections of the server concurrently.
+// Each connection stops accepting new requests and waits for its in-flight requests to finish until ctx is done.
+// The sessions started after Shutdown is called are refused by Serve.
+// See jsonrpc2.Conn.Shutdown for details.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.shuttingDown = true
+	conns := make([]*jsonrpc2.Conn, 0, len(s.connections))
+	for _, sess := range s.connections {
+		conns = append(conns, sess.conn)
+	}
+	s.mu.Unlock()
+
+	errs := make([]error, len(conns))
+	var wg sync.WaitGroup
+	for i, conn := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = conn.Shutdown(ctx)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
 }
 
 // Close closes the server.
@@ -153,8 +273,8 @@ func (s *Server) Close() error {
 	defer s.mu.Unlock()
 
 	var err error
-	for _, conn := range s.connections {
-		err = errors.Join(err, conn.Close())
+	for _, sess := range s.connections {
+		err = errors.Join(err, sess.conn.Close())
 	}
 
 	return err
@@ -2,7 +2,9 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/Warashi/go-modelcontextprotocol/jsonrpc2"
 	"github.com/Warashi/go-modelcontextprotocol/jsonschema"
@@ -39,6 +41,124 @@ func TestServer_Close(t *testing.T) {
 	}
 }
 
+func TestServer_ServeUnregistersConnection(t *testing.T) {
+	server := mustNewServer(t, "test", "1.0.0")
+
+	// Discard has no messages to receive, so Serve returns immediately.
+	server.Serve(t.Context(), 1, transport.Discard{})
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.connections) != 0 {
+		t.Errorf("expected no connections after Serve returns, got %d", len(server.connections))
+	}
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	a, b := transport.NewPipe()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := mustNewServer(t, "test", "1.0.0",
+		WithCustomHandler("slow", jsonrpc2.HandlerFunc[any, string](func(ctx context.Context, params any) (string, error) {
+			close(started)
+			<-release
+			return "done", nil
+		})),
+	)
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		server.Serve(t.Context(), 1, a)
+	}()
+
+	client := jsonrpc2.NewConnection(b)
+	client.Open()
+
+	called := make(chan error, 1)
+	go func() {
+		_, err := jsonrpc2.Call[string, any](t.Context(), client, "slow", struct{}{})
+		called <- err
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- server.Shutdown(t.Context()) }()
+	close(release)
+
+	if err := <-called; err != nil {
+		t.Errorf("in-flight call failed: %v", err)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	<-served
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.connections) != 0 {
+		t.Errorf("expected no connections after Shutdown, got %d", len(server.connections))
+	}
+}
+
+func TestServer_ShutdownRefusesNewSessions(t *testing.T) {
+	a, b := transport.NewPipe()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := mustNewServer(t, "test", "1.0.0",
+		WithCustomHandler("slow", jsonrpc2.HandlerFunc[any, string](func(ctx context.Context, params any) (string, error) {
+			close(started)
+			<-release
+			return "done", nil
+		})),
+	)
+	go server.Serve(t.Context(), 1, a)
+
+	client := jsonrpc2.NewConnection(b)
+	client.Open()
+	go jsonrpc2.Call[string, any](t.Context(), client, "slow", struct{}{})
+	<-started
+
+	// Shutdown waits for the in-flight call of the first session
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- server.Shutdown(t.Context()) }()
+	for {
+		server.mu.Lock()
+		shuttingDown := server.shuttingDown
+		server.mu.Unlock()
+		if shuttingDown {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// A session started while shutting down is refused
+	c, _ := transport.NewPipe()
+	if err := server.Serve(t.Context(), 2, c); !errors.Is(err, ErrServerShuttingDown) {
+		t.Errorf("Serve() during Shutdown error = %v; want ErrServerShuttingDown", err)
+	}
+
+	close(release)
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+
+	// And so is a session started after Shutdown returns
+	d, _ := transport.NewPipe()
+	if err := server.Serve(t.Context(), 3, d); !errors.Is(err, ErrServerShuttingDown) {
+		t.Errorf("Serve() after Shutdown error = %v; want ErrServerShuttingDown", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	for _, id := range []uint64{2, 3} {
+		if _, ok := server.connections[id]; ok {
+			t.Errorf("expected session %d not to be registered", id)
+		}
+	}
+}
+
 func TestServerOptions(t *testing.T) {
 	t.Run("WithCustomHandler", func(t *testing.T) {
 		handler := jsonrpc2.HandlerFunc[string, string](func(ctx context.Context, params string) (string, error) {
@@ -46,8 +166,8 @@ func TestServerOptions(t *testing.T) {
 		})
 		server := mustNewServer(t, "test", "1.0.0", WithCustomHandler("test_method", handler))
 
-		if len(server.initOpts) != 10 {
-			t.Errorf("expected 10 handlers, got %d", len(server.initOpts))
+		if len(server.initOpts) != 11 {
+			t.Errorf("expected 11 handlers, got %d", len(server.initOpts))
 		}
 	})
 
@@ -57,8 +177,8 @@ func TestServerOptions(t *testing.T) {
 		}
 		server := mustNewServer(t, "test", "1.0.0", WithCustomHandlerFunc("test_method", handlerFunc))
 
-		if len(server.initOpts) != 10 {
-			t.Errorf("expected 10 handlers, got %d", len(server.initOpts))
+		if len(server.initOpts) != 11 {
+			t.Errorf("expected 11 handlers, got %d", len(server.initOpts))
 		}
 	})
 
@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"maps"
 	"reflect"
 	"slices"
+	"strings"
 
 	"github.com/Warashi/go-modelcontextprotocol/jsonrpc2"
 	"github.com/Warashi/go-modelcontextprotocol/jsonschema"
@@ -74,7 +76,7 @@ type ToolCallResultData struct {
 func (s *Server) CallTool(ctx context.Context, request *Request[ToolCallRequestParams]) (*Result[ToolCallResultData], error) {
 	tool, ok := s.tools[request.Params.Name]
 	if !ok {
-		return nil, jsonrpc2.NewError(jsonrpc2.CodeMethodNotFound, "tool not found", struct{}{})
+		return nil, &ToolNotFoundError{Name: request.Params.Name}
 	}
 
 	result, err := tool.Handle(ctx, request.Params.Arguments)
@@ -97,6 +99,9 @@ type Tool[Input, Output any] struct {
 	InputSchema jsonschema.Object `json:"inputSchema"`
 	// Handler is the handler of the tool.
 	Handler ToolHandler[Input, Output] `json:"-"`
+	// HideInternalErrors keeps the text of errors returned by Handler out of the tool call result,
+	// except for a *ToolError, which is meant to be shown to the model.
+	HideInternalErrors bool `json:"-"`
 }
 
 // NewTool creates a new tool.
@@ -146,14 +151,15 @@ func (t Tool[Input, Output]) Handle(ctx context.Context, input json.RawMessage)
 			IsError: true,
 			Content: []IsContent{
 				&TextContent{
-					Text: err.Error(),
+					Text: validationErrorText(err),
 				},
 			},
 		}, nil
 	}
 
+	// The input is decoded by jsonschema.Unmarshal, so that the inline fields of Input are decoded as validated
 	var inputInput Input
-	if err := json.Unmarshal(input, &inputInput); err != nil {
+	if err := jsonschema.Unmarshal(input, &inputInput); err != nil {
 		return &ToolCallResultData{
 			IsError: true,
 			Content: []IsContent{
@@ -166,19 +172,62 @@ func (t Tool[Input, Output]) Handle(ctx context.Context, input json.RawMessage)
 
 	result, err := t.Handler.Handle(ctx, inputInput)
 	if err != nil {
-		return &ToolCallResultData{
-			IsError: true,
-			Content: []IsContent{
-				&TextContent{
-					Text: err.Error(),
-				},
-			},
-		}, nil
+		return t.handleError(err)
 	}
 
 	return convert(result), nil
 }
 
+// validationErrorText returns the text of the error of the input validation.
+// A *jsonschema.ValidationError is rendered with a line for each violation,
+// so that the model can fix all of them at once.
+func validationErrorText(err error) string {
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err.Error()
+	}
+
+	var sb strings.Builder
+	sb.WriteString("invalid input:")
+	for _, v := range validationErr.Violations {
+		sb.WriteString("\n- ")
+		sb.WriteString(v.String())
+	}
+	return sb.String()
+}
+
+// internalErrorText is the text shown to the model instead of a hidden internal error.
+const internalErrorText = "internal error"
+
+// handleError converts an error returned by the handler.
+// A *ToolError is reported in the tool call result.
+// A *ProtocolError or a jsonrpc2.ErrorCoder is returned as is, to be sent as a JSON-RPC 2.0 error.
+// Other errors are reported in the tool call result, with its text hidden if HideInternalErrors is set.
+func (t Tool[Input, Output]) handleError(err error) (*ToolCallResultData, error) {
+	text := err.Error()
+
+	var toolErr *ToolError
+	var protocolErr *ProtocolError
+	var coder jsonrpc2.ErrorCoder
+	switch {
+	case errors.As(err, &toolErr):
+		text = toolErr.Error()
+	case errors.As(err, &protocolErr), errors.As(err, &coder):
+		return nil, err
+	case t.HideInternalErrors:
+		text = internalErrorText
+	}
+
+	return &ToolCallResultData{
+		IsError: true,
+		Content: []IsContent{
+			&TextContent{
+				Text: text,
+			},
+		},
+	}, nil
+}
+
 // convert converts the result to the ToolCallResultData.
 // if the result is already a ToolCallResultData, it returns the result as is.
 // if the result is a slice, it converts each element.
@@ -3,6 +3,10 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
 	"testing"
 
 	"github.com/Warashi/go-modelcontextprotocol/jsonrpc2"
@@ -88,11 +92,15 @@ func TestServer_CallTool(t *testing.T) {
 		t.Error("expected error for nonexistent tool, got nil")
 		return
 	}
-	if jsonrpc2err, ok := err.(jsonrpc2.Error[struct{}]); !ok {
-		t.Errorf("unexpected error type: got %T, want jsonrpc2.Error[struct{}]", err)
+	var notFound *ToolNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("unexpected error type: got %T, want *ToolNotFoundError", err)
 		return
-	} else if jsonrpc2err.Code != jsonrpc2.CodeMethodNotFound {
-		t.Errorf("unexpected error code: got %v, want %v", jsonrpc2err.Code, jsonrpc2.CodeMethodNotFound)
+	} else if notFound.Name != "nonexistent" {
+		t.Errorf("unexpected tool name: got %q, want %q", notFound.Name, "nonexistent")
+	}
+	if !errors.Is(err, ErrToolNotFound) {
+		t.Errorf("expected error to match ErrToolNotFound, got %v", err)
 	}
 
 	// Test case 2: Successful tool call
@@ -177,3 +185,151 @@ func TestConvert(t *testing.T) {
 	}
 	assertJSONEqual(t, `{"isError":false,"content":[{"type":"text","text":"custom"}]}`, string(got))
 }
+
+func TestTool_HandleErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		hide       bool
+		wantResult string
+		wantErr    error
+	}{
+		{
+			name:       "internal error",
+			err:        errors.New("database is down"),
+			wantResult: `{"isError":true,"content":[{"type":"text","text":"database is down"}]}`,
+		},
+		{
+			name:       "hidden internal error",
+			err:        errors.New("database is down"),
+			hide:       true,
+			wantResult: `{"isError":true,"content":[{"type":"text","text":"internal error"}]}`,
+		},
+		{
+			name:       "tool error is shown even if hidden",
+			err:        fmt.Errorf("wrapped: %w", &ToolError{Err: errors.New("city not found")}),
+			hide:       true,
+			wantResult: `{"isError":true,"content":[{"type":"text","text":"city not found"}]}`,
+		},
+		{
+			name:    "protocol error",
+			err:     &ProtocolError{Err: errors.New("unsupported")},
+			wantErr: &ProtocolError{Err: errors.New("unsupported")},
+		},
+		{
+			name:    "invalid params error",
+			err:     &InvalidParamsError{Message: "invalid", Fields: []InvalidField{{Name: "city", Message: "unknown city"}}},
+			wantErr: &InvalidParamsError{Message: "invalid", Fields: []InvalidField{{Name: "city", Message: "unknown city"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := NewToolFunc("test", "Test tool", jsonschema.Object{}, func(ctx context.Context, input map[string]string) (string, error) {
+				return "", tt.err
+			})
+			tool.HideInternalErrors = tt.hide
+
+			result, err := tool.Handle(context.Background(), json.RawMessage(`{}`))
+			if tt.wantErr != nil {
+				if !reflect.DeepEqual(err, tt.wantErr) {
+					t.Errorf("Handle() error = %v; want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := json.Marshal(result)
+			if err != nil {
+				t.Fatalf("failed to marshal result: %v", err)
+			}
+			assertJSONEqual(t, tt.wantResult, string(got))
+		})
+	}
+}
+
+func TestTool_HandleValidationError(t *testing.T) {
+	schema := jsonschema.Object{
+		Properties: map[string]jsonschema.Schema{
+			"name":  jsonschema.String{MinLength: 3},
+			"count": jsonschema.Integer{},
+		},
+		Required: []string{"name", "count"},
+	}
+	tool := NewToolFunc("test", "Test tool", schema, func(ctx context.Context, input map[string]any) (string, error) {
+		t.Error("handler must not be called for invalid input")
+		return "", nil
+	})
+
+	result, err := tool.Handle(context.Background(), json.RawMessage(`{"name": "a", "extra": 1}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("failed to marshal result: %v", err)
+	}
+	assertJSONEqual(t, `{"isError":true,"content":[{"type":"text","text":"invalid input:\n- required property count not found\n- unexpected property extra\n- /name: string is too short"}]}`, string(got))
+}
+
+type inlineToolInput struct {
+	Query   string             `json:"query" jsonschema:"required"`
+	Options inlineToolOptions  `jsonschema:"inline"`
+	Extra   map[string]float64 `json:"-" jsonschema:"inline"`
+}
+
+type inlineToolOptions struct {
+	Limit int `json:"limit"`
+}
+
+func TestTool_HandleInlineInput(t *testing.T) {
+	schema, err := jsonschema.FromStruct(inlineToolInput{})
+	if err != nil {
+		t.Fatalf("failed to generate schema: %v", err)
+	}
+	tool := NewToolFunc("test", "Test tool", schema, func(ctx context.Context, input inlineToolInput) (string, error) {
+		want := inlineToolInput{
+			Query:   "q",
+			Options: inlineToolOptions{Limit: 10},
+			Extra:   map[string]float64{"boost": 1.5},
+		}
+		if !reflect.DeepEqual(input, want) {
+			t.Errorf("input = %+v; want %+v", input, want)
+		}
+		return "ok", nil
+	})
+
+	result, err := tool.Handle(context.Background(), json.RawMessage(`{"query": "q", "limit": 10, "boost": 1.5}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Errorf("unexpected error result: %+v", result)
+	}
+}
+
+func TestTool_HandleURLInput(t *testing.T) {
+	type input struct {
+		Target url.URL `json:"target" jsonschema:"required"`
+	}
+	schema, err := jsonschema.FromStruct(input{})
+	if err != nil {
+		t.Fatalf("failed to generate schema: %v", err)
+	}
+	tool := NewToolFunc("fetch", "Fetch tool", schema, func(ctx context.Context, in input) (string, error) {
+		return in.Target.Host, nil
+	})
+
+	result, err := tool.Handle(context.Background(), json.RawMessage(`{"target": "https://example.com/index.html"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("failed to marshal result: %v", err)
+	}
+	assertJSONEqual(t, `{"isError":false,"content":[{"type":"text","text":"example.com"}]}`, string(got))
+}
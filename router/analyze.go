@@ -0,0 +1,441 @@
+package router
+
+import (
+	"iter"
+	"maps"
+	"net/url"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	// Pattern is the URI pattern as passed to Handle, or the prefix passed to Mount.
+	Pattern string
+	// Name is the name of the route passed to HandleNamed, or empty.
+	Name string
+	// Params are the names of the params of the route in the order of appearance.
+	Params []string
+	// Priority is used to choose a route among the routes matching a URI; the higher is preferred.
+	// The route registered first is chosen among the routes with the same priority.
+	Priority int
+	// Mounted reports whether the route is a sub-mux mounted by Mount.
+	Mounted bool
+}
+
+// routeInfo returns the description of a route.
+func routeInfo[T any](r *route[T]) RouteInfo {
+	return RouteInfo{
+		Pattern:  r.pattern,
+		Name:     r.name,
+		Params:   paramNames(*r),
+		Priority: calcStaticScore(*r),
+		Mounted:  r.mount != nil,
+	}
+}
+
+// Routes returns an iterator over the registered routes in the order of registration.
+// The routes registered while iterating are not included.
+func (m *Mux[T]) Routes() iter.Seq[RouteInfo] {
+	m.mu.Lock()
+	routes := slices.Clone(m.routes)
+	m.mu.Unlock()
+
+	return func(yield func(RouteInfo) bool) {
+		for _, r := range routes {
+			if !yield(routeInfo(r)) {
+				return
+			}
+		}
+	}
+}
+
+// MatchResult is the result of Match.
+type MatchResult struct {
+	// Route is the route chosen for the URI, or nil if no route matches.
+	Route *RouteInfo
+	// Params are the params extracted by the chosen route.
+	Params map[string]string
+	// Candidates are the routes considered for the URI in the order of registration,
+	// including the ones that don't match.
+	Candidates []Candidate
+}
+
+// Candidate is a route considered by Match.
+type Candidate struct {
+	Route RouteInfo
+	// Matched reports whether the route matches the URI.
+	Matched bool
+}
+
+// Match returns the route chosen for the URI and the routes considered, without calling the handler.
+// Match doesn't look into mounted sub-muxes: a mounted sub-mux is returned as the chosen route.
+// Match returns an error if the URI cannot be parsed.
+func (m *Mux[T]) Match(uri string) (MatchResult, error) {
+	_, parsed, err := m.parseRequest(uri)
+	if err != nil {
+		return MatchResult{}, err
+	}
+
+	considered, matched := m.findCandidates(m.load(), parsed, nil)
+	var result MatchResult
+	for _, rt := range considered {
+		result.Candidates = append(result.Candidates, Candidate{
+			Route:   routeInfo(rt),
+			Matched: slices.ContainsFunc(matched, func(c matchedRoute[T]) bool { return c.route == rt }),
+		})
+	}
+	if len(matched) > 0 {
+		best := pickBestMatch(matched)
+		info := routeInfo(best.route)
+		result.Route = &info
+		result.Params = best.params
+	}
+	return result, nil
+}
+
+// Overlap is a pair of routes that match the same URI.
+type Overlap struct {
+	// Routes are the overlapping routes in the order of registration.
+	Routes [2]RouteInfo
+	// Example is a URI matching both routes.
+	Example string
+	// Chosen is the pattern of the route chosen for Example.
+	// It can be a third route that has a higher priority than both.
+	Chosen string
+	// Ambiguous reports whether the routes have the same priority,
+	// so that the route registered first is chosen for the URIs matching both.
+	Ambiguous bool
+}
+
+// Analyze reports the pairs of routes that match the same URI, in the order of registration.
+// An overlap is reported only if an example URI matching both routes is found,
+// so overlaps that need a specific value for a param with a complex constraint may not be reported.
+func (m *Mux[T]) Analyze() []Overlap {
+	m.mu.Lock()
+	routes := slices.Clone(m.routes)
+	m.mu.Unlock()
+	snapshot := m.load()
+
+	var overlaps []Overlap
+	for i, a := range routes {
+		for _, b := range routes[i+1:] {
+			example, ok := exampleURI(*a, *b)
+			if !ok {
+				continue
+			}
+			_, parsed, err := m.parseRequest(example)
+			if err != nil {
+				continue
+			}
+			// Confirm that the example matches both routes
+			if _, ok := m.matchRoute(*a, parsed); !ok {
+				continue
+			}
+			if _, ok := m.matchRoute(*b, parsed); !ok {
+				continue
+			}
+
+			overlap := Overlap{
+				Routes:    [2]RouteInfo{routeInfo(a), routeInfo(b)},
+				Example:   example,
+				Ambiguous: calcStaticScore(*a) == calcStaticScore(*b),
+			}
+			if _, matched := m.findCandidates(snapshot, parsed, nil); len(matched) > 0 {
+				overlap.Chosen = pickBestMatch(matched).route.pattern
+			}
+			overlaps = append(overlaps, overlap)
+		}
+	}
+	return overlaps
+}
+
+// exampleURI builds a URI that may match both routes.
+// exampleURI returns false if the routes cannot match the same URI.
+// The URI must be checked with matchRoute, as exampleURI only tries a few values for the params.
+func exampleURI[T any](a, b route[T]) (string, bool) {
+	if a.scheme != b.scheme {
+		return "", false
+	}
+
+	var host string
+	switch {
+	case !a.hostIsParam:
+		host = a.host
+	case !b.hostIsParam:
+		host = b.host
+	default:
+		host = sampleHost(a)
+	}
+
+	segs, ok := examplePath(a, b)
+	if !ok {
+		return "", false
+	}
+
+	var sb strings.Builder
+	sb.WriteString(a.scheme)
+	sb.WriteString("://")
+	sb.WriteString(host)
+	sb.WriteString("/")
+	for i, seg := range segs {
+		if i > 0 {
+			sb.WriteString("/")
+		}
+		sb.WriteString(url.PathEscape(seg))
+	}
+	if q := exampleQuery(a, b); q != "" {
+		sb.WriteString("?")
+		sb.WriteString(q)
+	}
+	return sb.String(), true
+}
+
+// sampleHost returns a host matching the host param of the route.
+func sampleHost[T any](r route[T]) string {
+	start := strings.Index(r.host, "{")
+	end := strings.Index(r.host, "}")
+	if start < 0 || end < start {
+		return r.host
+	}
+	return r.host[:start] + "x" + r.host[end+1:]
+}
+
+// examplePath builds the path segments that may match both routes.
+func examplePath[T any](a, b route[T]) ([]string, bool) {
+	la, lb := len(a.pathSegments), len(b.pathSegments)
+
+	// The number of segments
+	var n int
+	switch {
+	case a.tail == nil && b.tail == nil:
+		if la != lb {
+			return nil, false
+		}
+		n = la
+	case a.tail == nil:
+		n = la
+	case b.tail == nil:
+		n = lb
+	default:
+		n = max(la+a.tail.min, lb+b.tail.min)
+	}
+	for _, r := range []route[T]{a, b} {
+		l := len(r.pathSegments)
+		if n < l {
+			return nil, false
+		}
+		if r.tail == nil {
+			continue
+		}
+		if n < l+r.tail.min || (!r.tail.rest && n > l+len(r.tail.names)) {
+			return nil, false
+		}
+	}
+
+	segs := make([]string, n)
+	for i := range segs {
+		var sa, sb *pathSegment
+		if i < la {
+			sa = &a.pathSegments[i]
+		}
+		if i < lb {
+			sb = &b.pathSegments[i]
+		}
+		v, ok := sampleSegment(sa, sb)
+		if !ok {
+			return nil, false
+		}
+		segs[i] = v
+	}
+	return segs, true
+}
+
+// sampleSegment returns a value matching both segments.
+// A nil segment is a segment captured by a tail, which matches any value.
+func sampleSegment(a, b *pathSegment) (string, bool) {
+	var samples []string
+	for _, seg := range []*pathSegment{a, b} {
+		if seg != nil {
+			samples = append(samples, segmentSamples(*seg)...)
+		}
+	}
+	samples = append(samples, "x")
+
+	for _, v := range samples {
+		if (a == nil || matchSegment(*a, v)) && (b == nil || matchSegment(*b, v)) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// segmentSamples returns the values that match the segment.
+func segmentSamples(seg pathSegment) []string {
+	switch {
+	case seg.isParam && seg.constraint == nil:
+		return []string{"x"}
+	case seg.isParam:
+		return sampleRegexp(seg.constraint)
+	case seg.re != nil:
+		return sampleRegexp(seg.re)
+	default:
+		return []string{seg.literal}
+	}
+}
+
+// matchSegment reports whether the value matches the segment.
+func matchSegment(seg pathSegment, v string) bool {
+	switch {
+	case seg.isParam && seg.constraint == nil:
+		return v != ""
+	case seg.isParam:
+		return seg.constraint.MatchString(v)
+	case seg.re != nil:
+		return seg.re.MatchString(v)
+	default:
+		return seg.literal == v
+	}
+}
+
+// exampleQuery builds the query that may match both routes.
+// For each key, a value for the captures comes first, as the captures use the first value,
+// followed by the fixed values, as one of the values must match a fixed value.
+func exampleQuery[T any](a, b route[T]) string {
+	captures := make(map[string][]*regexp.Regexp)
+	fixed := make(map[string][]string)
+	for _, r := range []route[T]{a, b} {
+		for _, c := range r.queryCaptures {
+			captures[c.key] = append(captures[c.key], c.constraint)
+		}
+		for k, v := range r.query {
+			if !slices.Contains(fixed[k], v) {
+				fixed[k] = append(fixed[k], v)
+			}
+		}
+	}
+
+	keys := slices.Collect(maps.Keys(fixed))
+	for k := range captures {
+		if _, ok := fixed[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var pairs []string
+	for _, k := range keys {
+		if constraints, ok := captures[k]; ok {
+			pairs = append(pairs, url.QueryEscape(k)+"="+url.QueryEscape(sampleCapture(constraints, fixed[k])))
+		}
+		for _, v := range fixed[k] {
+			pairs = append(pairs, url.QueryEscape(k)+"="+url.QueryEscape(v))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// sampleCapture returns a value satisfying all the constraints of the captures of a key.
+// A nil constraint is satisfied by any value.
+func sampleCapture(constraints []*regexp.Regexp, fixed []string) string {
+	samples := slices.Clone(fixed)
+	for _, re := range constraints {
+		if re != nil {
+			samples = append(samples, sampleRegexp(re)...)
+		}
+	}
+	samples = append(samples, "x")
+
+	for _, v := range samples {
+		if !slices.ContainsFunc(constraints, func(re *regexp.Regexp) bool { return re != nil && !re.MatchString(v) }) {
+			return v
+		}
+	}
+	return "x"
+}
+
+// samplePreferences are the orders of the characters preferred in the samples of a character class.
+// A sample is generated for each order, so that the samples of different regular expressions are likely to meet.
+var samplePreferences = []string{"x0aA1", "a0xA1", "0ax1A", "A0ax1"}
+
+// sampleRegexp returns the values that match the regular expression, or nil if no value is found.
+func sampleRegexp(re *regexp.Regexp) []string {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	parsed = parsed.Simplify()
+
+	var samples []string
+	for _, prefer := range samplePreferences {
+		v, ok := sampleSyntax(parsed, prefer)
+		if ok && re.MatchString(v) && !slices.Contains(samples, v) {
+			samples = append(samples, v)
+		}
+	}
+	return samples
+}
+
+// sampleSyntax returns a short string matching the regular expression.
+// prefer is the order of the characters preferred in a character class.
+func sampleSyntax(re *syntax.Regexp, prefer string) (string, bool) {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary, syntax.OpStar, syntax.OpQuest:
+		return "", true
+	case syntax.OpLiteral:
+		return string(re.Rune), true
+	case syntax.OpCharClass:
+		return sampleCharClass(re.Rune, prefer)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return "x", true
+	case syntax.OpCapture, syntax.OpPlus:
+		return sampleSyntax(re.Sub[0], prefer)
+	case syntax.OpRepeat:
+		v, ok := sampleSyntax(re.Sub[0], prefer)
+		return strings.Repeat(v, re.Min), ok
+	case syntax.OpConcat:
+		var sb strings.Builder
+		for _, sub := range re.Sub {
+			v, ok := sampleSyntax(sub, prefer)
+			if !ok {
+				return "", false
+			}
+			sb.WriteString(v)
+		}
+		return sb.String(), true
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if v, ok := sampleSyntax(sub, prefer); ok {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
+// sampleCharClass returns a character in the class, preferring the characters in prefer.
+// ranges are the pairs of the lowest and highest characters of the class.
+func sampleCharClass(ranges []rune, prefer string) (string, bool) {
+	if len(ranges) == 0 {
+		return "", false
+	}
+	for _, c := range prefer {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if ranges[i] <= c && c <= ranges[i+1] {
+				return string(c), true
+			}
+		}
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		for c := ranges[i]; c <= ranges[i+1] && c <= unicode.MaxASCII; c++ {
+			if unicode.IsPrint(c) && c != ' ' {
+				return string(c), true
+			}
+		}
+	}
+	return string(ranges[0]), true
+}
@@ -0,0 +1,169 @@
+package router
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func newAnalyzeMux(t *testing.T, patterns ...string) *Mux[string] {
+	t.Helper()
+	m := NewMux[string]()
+	for _, p := range patterns {
+		if err := m.HandleFunc(p, func(ctx context.Context, req *Request) (string, error) {
+			return p, nil
+		}); err != nil {
+			t.Fatalf("HandleFunc(%s) error = %v", p, err)
+		}
+	}
+	return m
+}
+
+func TestMux_Routes(t *testing.T) {
+	m := NewMux[string]()
+	handler := func(ctx context.Context, req *Request) (string, error) { return "", nil }
+	if err := m.HandleFunc("http://{tenant}.example.com/users/{id:int}", handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.HandleFuncNamed("search", "http://example.com/search?q={q}{&page}", handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Mount("file://localhost/docs", NewMux[string]()); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []RouteInfo
+	for r := range m.Routes() {
+		got = append(got, r)
+	}
+	want := []RouteInfo{
+		{Pattern: "http://{tenant}.example.com/users/{id:int}", Params: []string{"tenant", "id"}, Priority: 1 + 3 + 2},
+		{Pattern: "http://example.com/search?q={q}{&page}", Name: "search", Params: []string{"q", "page"}, Priority: 3 + 3 + 1},
+		{Pattern: "file://localhost/docs", Priority: 3 + 3, Mounted: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Routes() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMux_Match(t *testing.T) {
+	m := newAnalyzeMux(t,
+		"http://example.com/users/{id}",
+		"http://example.com/users/{id:int}",
+		"http://example.com/users/me",
+	)
+
+	result, err := m.Match("http://example.com/users/42")
+	if err != nil {
+		t.Fatalf("Match() error = %v", err)
+	}
+	if result.Route == nil || result.Route.Pattern != "http://example.com/users/{id:int}" {
+		t.Errorf("Match().Route = %+v, want the constrained route", result.Route)
+	}
+	if !reflect.DeepEqual(result.Params, map[string]string{"id": "42"}) {
+		t.Errorf("Match().Params = %v, want id=42", result.Params)
+	}
+	var considered []string
+	for _, c := range result.Candidates {
+		if c.Matched {
+			considered = append(considered, c.Route.Pattern)
+		}
+	}
+	wantConsidered := []string{"http://example.com/users/{id}", "http://example.com/users/{id:int}"}
+	if !reflect.DeepEqual(considered, wantConsidered) {
+		t.Errorf("matched candidates = %v, want %v", considered, wantConsidered)
+	}
+
+	result, err = m.Match("http://example.com/posts/1")
+	if err != nil {
+		t.Fatalf("Match() error = %v", err)
+	}
+	if result.Route != nil || len(result.Candidates) != 0 {
+		t.Errorf("Match() = %+v, want no route", result)
+	}
+
+	if _, err := m.Match("example.com/users"); err == nil {
+		t.Error("Match() error = nil, want error for invalid URI")
+	}
+}
+
+func TestMux_Analyze(t *testing.T) {
+	m := newAnalyzeMux(t,
+		"http://example.com/users/{id}",
+		"http://example.com/users/me",
+		"http://example.com/files/{path...}",
+		"http://example.com/files/{dir}/{name}",
+		"http://example.com/commits/{sha:[0-9a-f]{40}}",
+		"http://example.com/commits/{ref:[a-z]+}",
+		"http://example.com/reports/{year:int}",
+		"http://example.com/reports/{month:[a-z]+}",
+		"http://example.com/search?q={q}",
+		"http://example.com/search?page={page:int}",
+	)
+
+	got := make(map[[2]string]Overlap)
+	for _, o := range m.Analyze() {
+		got[[2]string{o.Routes[0].Pattern, o.Routes[1].Pattern}] = o
+	}
+
+	tests := []struct {
+		routes        [2]string
+		wantChosen    string
+		wantAmbiguous bool
+	}{
+		{
+			routes:     [2]string{"http://example.com/users/{id}", "http://example.com/users/me"},
+			wantChosen: "http://example.com/users/me",
+		},
+		{
+			routes:     [2]string{"http://example.com/files/{path...}", "http://example.com/files/{dir}/{name}"},
+			wantChosen: "http://example.com/files/{dir}/{name}",
+		},
+		{
+			routes:        [2]string{"http://example.com/commits/{sha:[0-9a-f]{40}}", "http://example.com/commits/{ref:[a-z]+}"},
+			wantChosen:    "http://example.com/commits/{sha:[0-9a-f]{40}}",
+			wantAmbiguous: true,
+		},
+		{
+			routes:        [2]string{"http://example.com/search?q={q}", "http://example.com/search?page={page:int}"},
+			wantChosen:    "http://example.com/search?page={page:int}",
+			wantAmbiguous: false,
+		},
+	}
+	for _, tt := range tests {
+		o, ok := got[tt.routes]
+		if !ok {
+			t.Errorf("Analyze() doesn't report %v", tt.routes)
+			continue
+		}
+		if o.Chosen != tt.wantChosen {
+			t.Errorf("Analyze() chosen for %v = %s, want %s", tt.routes, o.Chosen, tt.wantChosen)
+		}
+		if o.Ambiguous != tt.wantAmbiguous {
+			t.Errorf("Analyze() ambiguous for %v = %v, want %v", tt.routes, o.Ambiguous, tt.wantAmbiguous)
+		}
+		for _, p := range tt.routes {
+			result, err := m.Match(o.Example)
+			if err != nil {
+				t.Fatalf("Match(%s) error = %v", o.Example, err)
+			}
+			matched := false
+			for _, c := range result.Candidates {
+				matched = matched || (c.Matched && c.Route.Pattern == p)
+			}
+			if !matched {
+				t.Errorf("example %s doesn't match %s", o.Example, p)
+			}
+		}
+	}
+
+	// The routes that cannot match the same URI are not reported
+	for _, routes := range [][2]string{
+		{"http://example.com/reports/{year:int}", "http://example.com/reports/{month:[a-z]+}"},
+		{"http://example.com/users/{id}", "http://example.com/files/{dir}/{name}"},
+	} {
+		if o, ok := got[routes]; ok {
+			t.Errorf("Analyze() reports %v with %s, want no overlap", routes, o.Example)
+		}
+	}
+}
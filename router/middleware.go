@@ -0,0 +1,104 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// mountMu serializes the mounts at prefixes without path segments,
+// so that two concurrent mounts cannot make a cycle unnoticed by mountsWithoutSegments.
+var mountMu sync.Mutex
+
+// Middleware wraps a Handler to add behavior such as authorization, caching or logging.
+type Middleware[T any] func(next Handler[T]) Handler[T]
+
+// Use appends middlewares to the Mux.
+// The middlewares wrap the handlers of all the routes, the mounted sub-muxes, and the not-found handler.
+// The first middleware is the outermost one.
+func (m *Mux[T]) Use(middlewares ...Middleware[T]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := *m.load()
+	// The middlewares of the current snapshot are shared with the readers, so they are copied instead of appended
+	s.middlewares = slices.Concat(s.middlewares, middlewares)
+	m.snapshot.Store(&s)
+}
+
+// chain wraps h with the middlewares.
+func chain[T any](h Handler[T], middlewares []Middleware[T]) Handler[T] {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// Mount mounts sub at prefix, so that the requests under prefix are handled by sub.
+// prefix is a URI pattern with a scheme, a host and an optional path, e.g. "tenant://{tenant}" or "file://localhost/docs/{version}".
+// sub receives the URI with the same scheme and host, and the path after the prefix;
+// for example, "file://localhost/docs/v1/guide/intro.md" is passed to sub as "file://localhost/guide/intro.md".
+//
+// The params from prefix are merged into Request.Params of sub.
+// If sub has a param with the same name, the param of sub is used.
+// If no route of sub matches, the not-found handler of sub is called, or ErrNotFound is returned.
+//
+// Like other routes, a more specific route of the Mux is prioritized over the mounted sub-mux,
+// and the mount can be removed by Unregister with prefix.
+//
+// A mount at a prefix without path segments passes the URI to sub unchanged,
+// so Mount returns an error if it makes a cycle of such mounts, which would never end.
+func (m *Mux[T]) Mount(prefix string, sub *Mux[T]) error {
+	if sub == nil || sub == m {
+		return fmt.Errorf("invalid sub-mux to mount at %s", prefix)
+	}
+
+	r, err := m.parseRoute(prefix, nil)
+	if err != nil {
+		return err
+	}
+	if r.tail != nil || len(r.query) > 0 || len(r.queryCaptures) > 0 || len(r.queryParams) > 0 || r.fragmentParam != "" {
+		return fmt.Errorf("mount prefix must consist of a scheme, a host and a fixed number of path segments: %s", prefix)
+	}
+	r.tail = &pathTail{rest: true}
+	r.mount = sub
+
+	if len(r.pathSegments) == 0 {
+		mountMu.Lock()
+		defer mountMu.Unlock()
+		if sub.mountsWithoutSegments(m, map[*Mux[T]]bool{}) {
+			return fmt.Errorf("mounting at %s makes a cycle of mounts without path segments", prefix)
+		}
+	}
+	return m.register(r)
+}
+
+// mountsWithoutSegments reports whether target is reachable from m through the mounts at prefixes without path segments.
+func (m *Mux[T]) mountsWithoutSegments(target *Mux[T], visited map[*Mux[T]]bool) bool {
+	if m == target {
+		return true
+	}
+	if visited[m] {
+		return false
+	}
+	visited[m] = true
+
+	m.mu.Lock()
+	routes := slices.Clone(m.routes)
+	m.mu.Unlock()
+
+	for _, r := range routes {
+		if r.mount != nil && len(r.pathSegments) == 0 && r.mount.mountsWithoutSegments(target, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// mountHandler returns a handler executing the URI on the mounted sub-mux, with the params of the prefix.
+func mountHandler[T any](sub *Mux[T], uri string) Handler[T] {
+	return HandlerFunc[T](func(ctx context.Context, req *Request) (T, error) {
+		return sub.execute(ctx, uri, req.Params)
+	})
+}
@@ -0,0 +1,207 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMux_Use(t *testing.T) {
+	m := NewMux[string]()
+	var calls []string
+	record := func(name string) Middleware[string] {
+		return func(next Handler[string]) Handler[string] {
+			return HandlerFunc[string](func(ctx context.Context, req *Request) (string, error) {
+				calls = append(calls, name+":before")
+				result, err := next.Handle(ctx, req)
+				calls = append(calls, name+":after")
+				return result, err
+			})
+		}
+	}
+	m.Use(record("outer"), record("inner"))
+	if err := m.HandleFunc("example://resource/{id}", func(ctx context.Context, req *Request) (string, error) {
+		calls = append(calls, "handler")
+		return req.Params["id"], nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := m.Execute(context.Background(), "example://resource/1")
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result != "1" {
+		t.Errorf("Execute() = %v, want 1", result)
+	}
+	want := "outer:before,inner:before,handler,inner:after,outer:after"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+
+	// The middlewares wrap the not-found handler as well
+	calls = nil
+	if _, err := m.Execute(context.Background(), "example://unknown/1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Execute() error = %v, want ErrNotFound", err)
+	}
+	want = "outer:before,inner:before,inner:after,outer:after"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestMux_UseShortCircuit(t *testing.T) {
+	m := NewMux[string]()
+	errUnauthorized := errors.New("unauthorized")
+	m.Use(func(next Handler[string]) Handler[string] {
+		return HandlerFunc[string](func(ctx context.Context, req *Request) (string, error) {
+			if req.Query["token"] != "secret" {
+				return "", errUnauthorized
+			}
+			return next.Handle(ctx, req)
+		})
+	})
+	if err := m.HandleFunc("example://resource/{id}", func(ctx context.Context, req *Request) (string, error) {
+		return "ok", nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Execute(context.Background(), "example://resource/1"); !errors.Is(err, errUnauthorized) {
+		t.Errorf("Execute() error = %v, want errUnauthorized", err)
+	}
+	if result, err := m.Execute(context.Background(), "example://resource/1?token=secret"); err != nil || result != "ok" {
+		t.Errorf("Execute() = %v, %v; want ok", result, err)
+	}
+}
+
+func TestMux_Mount(t *testing.T) {
+	sub := NewMux[string]()
+	handler := func(ctx context.Context, req *Request) (string, error) {
+		var parts []string
+		for _, k := range []string{"tenant", "version", "path", "id"} {
+			if v, ok := req.Params[k]; ok {
+				parts = append(parts, k+"="+v)
+			}
+		}
+		if q := req.Query["q"]; q != "" {
+			parts = append(parts, "q="+q)
+		}
+		return strings.Join(parts, ","), nil
+	}
+	for _, uri := range []string{
+		"file://localhost/guide/{path...}",
+		"tenant://{tenant}/users/{id}",
+	} {
+		if err := sub.HandleFunc(uri, handler); err != nil {
+			t.Fatalf("HandleFunc(%s) error = %v", uri, err)
+		}
+	}
+
+	m := NewMux[string]()
+	if err := m.Mount("file://localhost/docs/{version}", sub); err != nil {
+		t.Fatalf("Mount() error = %v", err)
+	}
+	if err := m.Mount("tenant://{tenant}", sub); err != nil {
+		t.Fatalf("Mount() error = %v", err)
+	}
+	if err := m.HandleFunc("file://localhost/docs/{version}/about", func(ctx context.Context, req *Request) (string, error) {
+		return "about", nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		uri     string
+		want    string
+		wantErr error
+	}{
+		{uri: "file://localhost/docs/v1/guide/intro%20page.md?q=x", want: "version=v1,path=intro page.md,q=x"},
+		{uri: "tenant://acme/users/42", want: "tenant=acme,id=42"},
+		// A more specific route of the parent is prioritized over the mount
+		{uri: "file://localhost/docs/v1/about", want: "about"},
+		// Not found in the sub-mux
+		{uri: "file://localhost/docs/v1/unknown", wantErr: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			got, err := m.Execute(context.Background(), tt.uri)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Execute() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Execute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if !m.Unregister("tenant://{tenant}") {
+		t.Error("Unregister() = false, want true for the mount")
+	}
+	if _, err := m.Execute(context.Background(), "tenant://acme/users/42"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Execute() error = %v, want ErrNotFound after unmounting", err)
+	}
+}
+
+func TestMux_MountErrors(t *testing.T) {
+	m := NewMux[string]()
+	sub := NewMux[string]()
+
+	for _, prefix := range []string{
+		"file://localhost/docs/{path...}",
+		"file://localhost/docs?version=1",
+		"file://localhost/docs{#section}",
+	} {
+		if err := m.Mount(prefix, sub); err == nil {
+			t.Errorf("Mount(%s) error = nil, want error", prefix)
+		}
+	}
+	if err := m.Mount("file://localhost/docs", m); err == nil {
+		t.Error("Mount() error = nil, want error for mounting itself")
+	}
+	if err := m.Mount("file://localhost/docs", sub); err != nil {
+		t.Fatalf("Mount() error = %v", err)
+	}
+	if err := m.HandleFunc("file://localhost/docs/{path...}", func(ctx context.Context, req *Request) (string, error) {
+		return "", nil
+	}); err == nil {
+		t.Error("HandleFunc() error = nil, want conflict with the mount")
+	}
+}
+
+func TestMux_MountCycle(t *testing.T) {
+	a, b, c := NewMux[string](), NewMux[string](), NewMux[string]()
+
+	if err := a.Mount("c://h", b); err != nil {
+		t.Fatalf("Mount() error = %v", err)
+	}
+	if err := b.Mount("c://{host}", c); err != nil {
+		t.Fatalf("Mount() error = %v", err)
+	}
+	if err := c.Mount("c://h", a); err == nil {
+		t.Fatal("Mount() error = nil, want error for a cycle of mounts without path segments")
+	}
+
+	// A cycle through a prefix with path segments ends as the path gets shorter
+	if err := c.Mount("c://h/loop", a); err != nil {
+		t.Fatalf("Mount() error = %v", err)
+	}
+	if _, err := a.Execute(context.Background(), "c://h/loop/loop/x"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Execute() error = %v, want ErrNotFound", err)
+	}
+
+	// Once the mount is removed, the remaining mounts don't make a cycle
+	if !a.Unregister("c://h") {
+		t.Fatal("Unregister() = false, want true for the mount")
+	}
+	if err := c.Mount("c://h", a); err != nil {
+		t.Errorf("Mount() error = %v", err)
+	}
+}
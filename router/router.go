@@ -3,25 +3,86 @@
 package router
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
-// ErrNotFound is returned when no matching route is found.
-var ErrNotFound = errors.New("route not found")
+var (
+	// ErrNotFound is returned when no matching route is found.
+	ErrNotFound = errors.New("route not found")
+	// ErrInvalidURI is returned when the request URI cannot be parsed.
+	ErrInvalidURI = errors.New("invalid URI")
+)
 
 // Request represents an incoming request with query parameters and path parameters.
 type Request struct {
 	// Query contains the query parameters from the URL.
+	// If a key has multiple values, Query contains the first one; see QueryValues for all the values.
 	Query map[string]string
+	// QueryValues contains all the values of the query parameters from the URL.
+	QueryValues url.Values
 	// Params contains the dynamic parameters extracted from the URL path and host.
 	Params map[string]string
 }
 
+// ParamInt returns the param as an int.
+// It returns an error if the param is missing or not an integer.
+func (r *Request) ParamInt(name string) (int, error) {
+	v, err := r.param(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(v)
+}
+
+// ParamInt64 returns the param as an int64.
+// It returns an error if the param is missing or not an integer.
+func (r *Request) ParamInt64(name string) (int64, error) {
+	v, err := r.param(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
+
+// ParamFloat64 returns the param as a float64.
+// It returns an error if the param is missing or not a number.
+func (r *Request) ParamFloat64(name string) (float64, error) {
+	v, err := r.param(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(v, 64)
+}
+
+// ParamBool returns the param as a bool.
+// It returns an error if the param is missing or not a boolean accepted by strconv.ParseBool.
+func (r *Request) ParamBool(name string) (bool, error) {
+	v, err := r.param(name)
+	if err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(v)
+}
+
+// param returns the param, or an error if it is missing.
+func (r *Request) param(name string) (string, error) {
+	v, ok := r.Params[name]
+	if !ok {
+		return "", fmt.Errorf("param not found: %s", name)
+	}
+	return v, nil
+}
+
 // Handler is an interface that processes requests and returns results of type T.
 type Handler[T any] interface {
 	Handle(ctx context.Context, req *Request) (T, error)
@@ -37,32 +98,94 @@ func (f HandlerFunc[T]) Handle(ctx context.Context, req *Request) (T, error) {
 
 // route represents a registered route with its pattern and handler.
 type route[T any] struct {
-	scheme string // stored in lowercase (fixed values only)
+	pattern string  // the URI pattern as registered
+	name    string  // the name of the route for URL, or empty
+	seq     uint64  // the sequence number of the registration, used to prefer the earlier route on a tie
+	mount   *Mux[T] // the mounted sub-mux handling the rest of the path, or nil
+	scheme  string  // stored in lowercase (fixed values only)
 	// host parameters
 	hostIsParam   bool
 	hostParamName string // used when hostIsParam == true
 	host          string // used when hostIsParam == false (lowercase)
 	// path segments
 	pathSegments []pathSegment
+	// tail captures the path segments after pathSegments, or nil if the path has a fixed number of segments
+	tail *pathTail
 	// query parameters (fixed keys and fixed values only)
 	query map[string]string
+	// queryCaptures are the required query parameters captured by "key={param}", sorted by key
+	queryCaptures []queryCapture
+	// queryParams are the names of the optional query parameters captured by "{?x,y}"
+	queryParams []string
+	// fragmentParam is the name of the param capturing the fragment by "{#frag}", or empty
+	fragmentParam string
 	// handler for processing requests
 	handler Handler[T]
 }
 
 // pathSegment represents a single segment of a URL path, which can be either
-// static (literal) or dynamic (parameter).
+// static (literal), dynamic (parameter), or a mix of literals and parameters.
 type pathSegment struct {
-	isParam   bool
-	paramName string // used when isParam = true
-	literal   string // used when isParam = false
+	isParam    bool
+	paramName  string         // used when isParam = true
+	constraint *regexp.Regexp // used when isParam = true, or nil if the param is not constrained
+	literal    string         // used when isParam = false and parts is empty
+	// parts is set for a segment mixing literals and parameters, e.g. "file-{name}.txt"
+	parts []segmentPart
+	// re matches a segment composed of parts, capturing the parameters in order
+	re *regexp.Regexp
+}
+
+// queryCapture is a required query parameter captured by "key={param}".
+type queryCapture struct {
+	key        string
+	paramName  string
+	constraint *regexp.Regexp // nil if the param is not constrained
+}
+
+// segmentPart is a literal or a parameter in a segment mixing literals and parameters.
+type segmentPart struct {
+	literal    string
+	paramName  string // set if the part is a parameter
+	maxLen     int    // maximum length of the parameter in characters, or 0 if not limited
+	constraint string // regular expression constraining the parameter, or empty
+}
+
+// pathTail captures a variable number of trailing path segments, e.g. "{+path}", "{path...}" or "{/segments*}".
+type pathTail struct {
+	// op is the operator of the expression, or 0 for "{path...}"
+	op byte
+	// names are the names of the params capturing the segments in order
+	names []string
+	// rest is set if the last param captures all the remaining segments joined by "/"
+	rest bool
+	// min is the minimum number of segments
+	min int
 }
 
 // Mux is a request multiplexer that matches incoming requests against registered
 // patterns and calls the corresponding handler.
+//
+// Mux is safe for concurrent use by multiple goroutines.
+// Execute doesn't take a lock: it reads an immutable snapshot of the routes.
+// A change to the routes copies only the nodes of the tree on the path of the route, and publishes a new snapshot atomically.
 type Mux[T any] struct {
-	routes          []route[T]
+	// mu guards the fields below, and serializes the changes to the snapshot
+	mu        sync.Mutex
+	routes    []*route[T] // in the order of registration, that is, sorted by seq
+	byPattern map[string]*route[T]
+	names     map[string]*route[T]
+	seq       uint64 // sequence number of the last registered route
+
+	// snapshot is the current state of the routes used by Execute, or nil if nothing is registered yet.
+	snapshot atomic.Pointer[muxSnapshot[T]]
+}
+
+// muxSnapshot is an immutable state of the routes of a Mux.
+type muxSnapshot[T any] struct {
+	tree            routeTree[T]
 	notFoundHandler Handler[T]
+	middlewares     []Middleware[T]
 }
 
 // NewMux creates a new Mux instance.
@@ -72,12 +195,26 @@ func NewMux[T any]() *Mux[T] {
 
 // SetNotFoundHandler sets the handler to be called when no matching route is found.
 func (m *Mux[T]) SetNotFoundHandler(h Handler[T]) {
-	m.notFoundHandler = h
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := *m.load()
+	s.notFoundHandler = h
+	m.snapshot.Store(&s)
 }
 
 // SetNotFoundHandlerFunc sets a function to be called when no matching route is found.
 func (m *Mux[T]) SetNotFoundHandlerFunc(f func(ctx context.Context, req *Request) (T, error)) {
-	m.notFoundHandler = HandlerFunc[T](f)
+	m.SetNotFoundHandler(HandlerFunc[T](f))
+}
+
+// load returns the current snapshot of the routes.
+// The snapshot must not be modified; a change stores a modified copy of it.
+func (m *Mux[T]) load() *muxSnapshot[T] {
+	if s := m.snapshot.Load(); s != nil {
+		return s
+	}
+	return &muxSnapshot[T]{}
 }
 
 // HandleFunc registers a new route with a handler function.
@@ -90,24 +227,121 @@ func (m *Mux[T]) HandleFunc(uri string, f func(context.Context, *Request) (T, er
 // uri can contains dynamic parameters like {param} in path and host.
 // uri can contains query parameters like ?key=value.
 // for example, "http://example.com/users/{id}" is a valid uri.
+//
+// uri is an RFC 6570 URI template, and the following expressions are supported in addition to {param}:
+//   - {+path} captures the rest of the path including slashes, e.g. "file:///{+path}"
+//   - {/a,b} captures up to two segments, and {/segments*} captures the rest of the path
+//   - {?x,y} and {&z} capture the optional query parameters x, y and z
+//   - page={page} captures the required query parameter page, e.g. "http://example.com/users?page={page:int}"
+//   - {#frag} captures the fragment
+//   - a parameter can be mixed with literals in a segment, e.g. "/file-{name}.txt" or "/{name}{.ext}"
+//
+// The following extensions are supported in the path as well:
+//   - {path...} captures the rest of the path including slashes, and matches an empty rest as well
+//   - {id:int} constrains the param by a type, which is one of int, uint, float, bool and uuid
+//   - {sha:[0-9a-f]{40}} constrains the param by a regular expression matching the whole value
+//
+// A param with a constraint is prioritized over a param without a constraint.
+// Routes that differ only by query are prioritized by the number of fixed query parameters and then required captures,
+// and the route registered first is used on a tie.
 func (m *Mux[T]) Handle(uri string, h Handler[T]) error {
+	return m.handle("", uri, h)
+}
+
+// handle registers a new route with a handler and an optional name.
+func (m *Mux[T]) handle(name, uri string, h Handler[T]) error {
 	// Parse URI → Convert to internal route[T] structure
 	r, err := m.parseRoute(uri, h)
 	if err != nil {
 		return err
 	}
+	r.name = name
+	return m.register(r)
+}
+
+// register adds a parsed route.
+func (m *Mux[T]) register(r route[T]) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name := r.name
+	if name != "" {
+		if _, ok := m.names[name]; ok {
+			return fmt.Errorf("route name duplicated: %s", name)
+		}
+	}
 	// Check for duplicates and conflicts during registration
-	if err := m.checkConflict(r); err != nil {
-		return err
+	// Routes with the same coverage always share the same node in the tree
+	s := *m.load()
+	if node := s.tree.node(&r); node != nil {
+		if err := m.checkConflict(r, *node.siblings(&r)); err != nil {
+			return err
+		}
 	}
 	// Add
-	m.routes = append(m.routes, r)
+	m.seq++
+	r.seq = m.seq
+	m.routes = append(m.routes, &r)
+	if m.byPattern == nil {
+		m.byPattern = make(map[string]*route[T])
+	}
+	m.byPattern[r.pattern] = &r
+	s.tree = s.tree.insert(&r)
+	if name != "" {
+		if m.names == nil {
+			m.names = make(map[string]*route[T])
+		}
+		m.names[name] = &r
+	}
+	m.snapshot.Store(&s)
 	return nil
 }
 
+// Unregister removes the route registered with the URI pattern uri.
+// uri must be the same string as passed to Handle.
+// Unregister reports whether the route existed.
+func (m *Mux[T]) Unregister(uri string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.byPattern[uri]
+	if !ok {
+		return false
+	}
+	i, _ := slices.BinarySearchFunc(m.routes, r.seq, func(rt *route[T], seq uint64) int { return cmp.Compare(rt.seq, seq) })
+	m.routes = slices.Delete(m.routes, i, i+1)
+	delete(m.byPattern, uri)
+	s := *m.load()
+	s.tree = s.tree.remove(r)
+	if r.name != "" {
+		delete(m.names, r.name)
+	}
+	m.snapshot.Store(&s)
+	return true
+}
+
+// Patterns returns the URI patterns of the registered routes in the order of registration.
+// The patterns are returned as passed to Handle.
+func (m *Mux[T]) Patterns() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	patterns := make([]string, len(m.routes))
+	for i, rt := range m.routes {
+		patterns[i] = rt.pattern
+	}
+	return patterns
+}
+
 // Execute processes an incoming request URI and calls the appropriate handler.
 // It returns ErrNotFound if no matching route is found and no notFoundHandler is set.
 func (m *Mux[T]) Execute(ctx context.Context, rawURI string) (T, error) {
+	return m.execute(ctx, rawURI, nil)
+}
+
+// execute processes a request URI like Execute.
+// inherited are the params from the prefix of a mount, which are set to Request.Params before the params of the route.
+func (m *Mux[T]) execute(ctx context.Context, rawURI string, inherited map[string]string) (T, error) {
 	var zero T
 
 	// Parse
@@ -116,24 +350,17 @@ func (m *Mux[T]) Execute(ctx context.Context, rawURI string) (T, error) {
 		// Return the parse error directly instead of treating it as a route mismatch
 		return zero, err
 	}
-
-	// Scan all routes to find the one with highest match score
-	var candidates []matchedRoute[T]
-	for _, rt := range m.routes {
-		params, match := m.matchRoute(rt, parsed)
-		if match {
-			// Add to candidates with acquired parameters
-			score := calcStaticScore(rt) // Use number of static segments as score
-			candidates = append(candidates, matchedRoute[T]{
-				route:  rt,
-				params: params,
-				score:  score,
-			})
-		}
+	for k, v := range inherited {
+		req.Params[k] = v
 	}
+
+	// Scan the candidate routes found by the tree to find the one with highest match score
+	snapshot := m.load()
+	var buf [8]*route[T]
+	_, candidates := m.findCandidates(snapshot, parsed, buf[:0])
 	if len(candidates) == 0 {
 		// Not found, return notFoundHandler or ErrNotFound
-		return m.handleNotFound(ctx, req)
+		return chain(notFoundHandler(snapshot.notFoundHandler), snapshot.middlewares).Handle(ctx, req)
 	}
 
 	// Sort by static score in descending order and select highest score
@@ -145,12 +372,37 @@ func (m *Mux[T]) Execute(ctx context.Context, rawURI string) (T, error) {
 		req.Params[k] = v
 	}
 
-	return best.route.handler.Handle(ctx, req)
+	h := best.route.handler
+	if best.route.mount != nil {
+		h = mountHandler(best.route.mount, parsed.rest(len(best.route.pathSegments)))
+	}
+	return chain(h, snapshot.middlewares).Handle(ctx, req)
+}
+
+// findCandidates returns the routes considered for the parsed URI, which are found by the tree,
+// and the routes matching the URI among them with their params and scores.
+// buf is used to store the considered routes.
+func (m *Mux[T]) findCandidates(snapshot *muxSnapshot[T], parsed *parsedURI, buf []*route[T]) ([]*route[T], []matchedRoute[T]) {
+	considered := snapshot.tree.candidates(buf, parsed)
+	var candidates []matchedRoute[T]
+	for _, rt := range considered {
+		params, match := m.matchRoute(*rt, parsed)
+		if match {
+			// Add to candidates with acquired parameters
+			score := calcStaticScore(*rt) // Use number of static segments as score
+			candidates = append(candidates, matchedRoute[T]{
+				route:  rt,
+				params: params,
+				score:  score,
+			})
+		}
+	}
+	return considered, candidates
 }
 
 // matchedRoute holds a matched route along with its extracted parameters and match score.
 type matchedRoute[T any] struct {
-	route  route[T]
+	route  *route[T]
 	params map[string]string
 	score  int
 }
@@ -166,56 +418,76 @@ func pickBestMatch[T any](candidates []matchedRoute[T]) matchedRoute[T] {
 	return best
 }
 
+// Scores of the parts of a route used by calcStaticScore.
+// More specific parts score higher; a tail scores nothing.
+const (
+	scoreStatic      = 3
+	scoreMixed       = 2
+	scoreConstrained = 2
+	scoreParam       = 1
+)
+
 // calcStaticScore calculates a score for a route based on its static segments.
-// Static segments and fixed hosts contribute to a higher score.
+// Static segments and fixed hosts contribute to a higher score,
+// followed by segments mixing literals and parameters, and then parameters.
 func calcStaticScore[T any](r route[T]) int {
-	score := 0
-	// +1 if host is fixed
+	score := scoreParam
 	if !r.hostIsParam {
-		score++
+		score = scoreStatic
 	}
-	// +1 for each static path segment
 	for _, seg := range r.pathSegments {
-		if !seg.isParam {
-			score++
+		switch {
+		case seg.isParam && seg.constraint != nil:
+			score += scoreConstrained
+		case seg.isParam:
+			score += scoreParam
+		case len(seg.parts) > 0:
+			score += scoreMixed
+		default:
+			score += scoreStatic
+		}
+	}
+	// Routes that differ only by query are disambiguated by the number of the query conditions
+	score += len(r.query) * scoreStatic
+	for _, c := range r.queryCaptures {
+		if c.constraint != nil {
+			score += scoreConstrained
+		} else {
+			score += scoreParam
 		}
 	}
 	return score
 }
 
-// handleNotFound processes requests when no matching route is found.
-func (m *Mux[T]) handleNotFound(ctx context.Context, req *Request) (T, error) {
-	var zero T
-	if m.notFoundHandler != nil {
-		return m.notFoundHandler.Handle(ctx, req)
+// notFoundHandler returns h, or a handler returning ErrNotFound if h is nil.
+func notFoundHandler[T any](h Handler[T]) Handler[T] {
+	if h != nil {
+		return h
 	}
-	return zero, ErrNotFound
+	return HandlerFunc[T](func(ctx context.Context, req *Request) (T, error) {
+		var zero T
+		return zero, ErrNotFound
+	})
 }
 
 // parseRoute converts a URI string into a route structure.
+// uri is a URI template; see Handle for the supported expressions.
 func (m *Mux[T]) parseRoute(uri string, h Handler[T]) (route[T], error) {
-	// Extract scheme and host manually first
+	// Extract scheme first
 	parts := strings.SplitN(uri, "://", 2)
 	if len(parts) != 2 {
 		return route[T]{}, fmt.Errorf("scheme is required")
 	}
 	scheme := strings.ToLower(parts[0])
 
-	// Split host and path+query
-	remainder := parts[1]
-	slashIndex := strings.Index(remainder, "/")
-	var host, pathAndQuery string
-	if slashIndex == -1 {
-		host = remainder
-		pathAndQuery = "/"
-	} else {
-		host = remainder[:slashIndex]
-		pathAndQuery = remainder[slashIndex:]
+	tokens, err := tokenizeTemplate(parts[1])
+	if err != nil {
+		return route[T]{}, fmt.Errorf("invalid URI template: %w", err)
 	}
+	hostTokens, pathTokens, queryTokens, fragmentTokens := splitTemplate(tokens)
 
-	if host == "" {
-		return route[T]{}, fmt.Errorf("host is required")
-	}
+	// The host may be empty, e.g. "file:///{path...}"
+	host := rawTemplate(hostTokens)
 
 	// Check if host contains parameter
 	hostIsParam := false
@@ -234,49 +506,372 @@ func (m *Mux[T]) parseRoute(uri string, h Handler[T]) (route[T], error) {
 		hostIsParam = true
 	}
 
-	// Parse path and query
-	u, err := url.Parse(scheme + "://example.com" + pathAndQuery)
-	if err != nil {
-		return route[T]{}, fmt.Errorf("invalid URI: %w", err)
-	}
-
 	// path → normalize (consecutive slashes/trailing slash etc.)
-	pathSegs, err := parsePath(u.Path)
+	pathSegs, tail, err := parsePathTemplate(pathTokens)
 	if err != nil {
 		return route[T]{}, err
 	}
 
-	// query (fixed key-value pairs only)
-	q, err := parseQueryForRegistration(u.RawQuery)
+	// query (fixed key-value pairs, required captures by "key={param}", and optional captures by "{?x,y}")
+	q, queryCaptures, queryParams, err := parseQueryTemplate(queryTokens)
 	if err != nil {
 		return route[T]{}, err
 	}
 
-	// Check for duplicate parameter names if dynamic parameters are included
-	if err := checkParamNameDuplication(hostParamName, pathSegs); err != nil {
+	// fragment (only captures by "{#frag}" are matched)
+	fragmentParam, err := parseFragmentTemplate(fragmentTokens)
+	if err != nil {
 		return route[T]{}, err
 	}
 
 	r := route[T]{
+		pattern:       uri,
 		scheme:        scheme,
 		hostIsParam:   hostIsParam,
 		hostParamName: hostParamName,
 		host:          strings.ToLower(host),
 		pathSegments:  pathSegs,
+		tail:          tail,
 		query:         q,
+		queryCaptures: queryCaptures,
+		queryParams:   queryParams,
+		fragmentParam: fragmentParam,
 		handler:       h,
 	}
+
+	// Check for duplicate parameter names if dynamic parameters are included
+	if err := checkParamNameDuplication(r); err != nil {
+		return route[T]{}, err
+	}
+
 	return r, nil
 }
 
+// splitTemplate splits the tokens of a URI template after "scheme://" into the host, path, query and fragment sections.
+// The delimiters "?" and "#" are removed, while "/" is kept at the start of the path.
+// Expressions with the "/" operator start the path, "?" and "&" start the query, and "#" starts the fragment.
+func splitTemplate(tokens []templateToken) (host, path, query, fragment []templateToken) {
+	const (
+		sectionHost = iota
+		sectionPath
+		sectionQuery
+		sectionFragment
+	)
+	var sections [4][]templateToken
+	section := sectionHost
+
+	for _, t := range tokens {
+		if t.expr != nil {
+			switch t.expr.op {
+			case '/':
+				section = max(section, sectionPath)
+			case '?', '&':
+				section = max(section, sectionQuery)
+			case '#':
+				section = sectionFragment
+			}
+			sections[section] = append(sections[section], t)
+			continue
+		}
+
+		lit := t.literal
+		for lit != "" {
+			var delimiters string
+			switch section {
+			case sectionHost:
+				delimiters = "/?#"
+			case sectionPath:
+				delimiters = "?#"
+			case sectionQuery:
+				delimiters = "#"
+			}
+			i := strings.IndexAny(lit, delimiters)
+			if delimiters == "" || i < 0 {
+				sections[section] = append(sections[section], templateToken{literal: lit})
+				break
+			}
+			if i > 0 {
+				sections[section] = append(sections[section], templateToken{literal: lit[:i]})
+			}
+			switch lit[i] {
+			case '/':
+				section = sectionPath
+				lit = lit[i:]
+			case '?':
+				section = sectionQuery
+				lit = lit[i+1:]
+			case '#':
+				section = sectionFragment
+				lit = lit[i+1:]
+			}
+		}
+	}
+
+	return sections[sectionHost], sections[sectionPath], sections[sectionQuery], sections[sectionFragment]
+}
+
+// rawTemplate joins the tokens back into the template text.
+func rawTemplate(tokens []templateToken) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		if t.expr == nil {
+			sb.WriteString(t.literal)
+		} else {
+			sb.WriteString(t.raw)
+		}
+	}
+	return sb.String()
+}
+
+// parsePathTemplate parses the path of a URI template into segments and an optional tail.
+// It removes consecutive slashes, trailing slashes, and empty segments.
+// "{+path}" as a whole segment and "{/segments*}" capture the rest of the path, and must be at the end of the path.
+func parsePathTemplate(tokens []templateToken) ([]pathSegment, *pathTail, error) {
+	var segs []pathSegment
+	var tail *pathTail
+	var current []templateToken
+	flush := func() error {
+		if len(current) == 0 {
+			return nil
+		}
+		seg, err := parseSegment(current)
+		if err != nil {
+			return err
+		}
+		segs = append(segs, seg)
+		current = nil
+		return nil
+	}
+
+	for _, t := range tokens {
+		if tail != nil {
+			// Only a trailing slash is allowed after the tail
+			if t.expr == nil && strings.Trim(t.literal, "/") == "" {
+				continue
+			}
+			return nil, nil, fmt.Errorf("path expression must be at the end of the path: %s", rawTemplate(tokens))
+		}
+
+		if t.expr == nil {
+			for i, piece := range strings.Split(t.literal, "/") {
+				if i > 0 {
+					if err := flush(); err != nil {
+						return nil, nil, err
+					}
+				}
+				if piece != "" {
+					current = append(current, templateToken{literal: piece})
+				}
+			}
+			continue
+		}
+
+		switch t.expr.op {
+		case '/', '+':
+			if t.expr.op == '+' && len(current) > 0 {
+				return nil, nil, fmt.Errorf("reserved expansion must be a whole path segment: %s", t.raw)
+			}
+			if err := flush(); err != nil {
+				return nil, nil, err
+			}
+			var err error
+			tail, err = parsePathTail(t.expr)
+			if err != nil {
+				return nil, nil, err
+			}
+		case 0:
+			if len(t.expr.vars) == 1 && t.expr.vars[0].catchAll {
+				if len(current) > 0 {
+					return nil, nil, fmt.Errorf("catch-all param must be a whole path segment: %s", t.raw)
+				}
+				if err := flush(); err != nil {
+					return nil, nil, err
+				}
+				if !isValidParamName(t.expr.vars[0].name) {
+					return nil, nil, fmt.Errorf("invalid path param name: %s", t.expr.vars[0].name)
+				}
+				// {path...} captures zero or more segments
+				tail = &pathTail{names: []string{t.expr.vars[0].name}, rest: true}
+				continue
+			}
+			current = append(current, t)
+		default:
+			current = append(current, t)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, nil, err
+	}
+
+	return segs, tail, nil
+}
+
+// parsePathTail parses an expression capturing the rest of the path.
+func parsePathTail(e *expression) (*pathTail, error) {
+	if err := validateExpression(e); err != nil {
+		return nil, fmt.Errorf("invalid path param name: %w", err)
+	}
+
+	tail := &pathTail{op: e.op}
+	for i, v := range e.vars {
+		if v.catchAll || v.constraint != "" {
+			return nil, fmt.Errorf("catch-all and constraint are not supported in path expression: %s", v.name)
+		}
+		if v.prefix > 0 {
+			return nil, fmt.Errorf("prefix modifier is not supported in path: %s", v.name)
+		}
+		if v.explode && i != len(e.vars)-1 {
+			return nil, fmt.Errorf("explode modifier is only supported on the last variable: %s", v.name)
+		}
+		tail.names = append(tail.names, v.name)
+	}
+
+	if e.op == '+' {
+		// {+path} captures one or more segments including slashes
+		if len(e.vars) != 1 || e.vars[0].explode {
+			return nil, fmt.Errorf("reserved expansion in path must have a single variable")
+		}
+		tail.rest = true
+		tail.min = 1
+		return tail, nil
+	}
+
+	// {/a,b} captures up to len(names) segments, {/a*} captures any number of segments
+	tail.rest = e.vars[len(e.vars)-1].explode
+	return tail, nil
+}
+
+// parseSegment parses the tokens of a single path segment.
+func parseSegment(tokens []templateToken) (pathSegment, error) {
+	if len(tokens) == 1 && tokens[0].expr == nil {
+		literal, err := url.PathUnescape(tokens[0].literal)
+		if err != nil {
+			return pathSegment{}, fmt.Errorf("%w: %w", ErrInvalidURI, err)
+		}
+		return pathSegment{literal: literal}, nil
+	}
+
+	if len(tokens) == 1 && tokens[0].expr.op == 0 && len(tokens[0].expr.vars) == 1 {
+		v := tokens[0].expr.vars[0]
+		if !isValidParamName(v.name) {
+			return pathSegment{}, fmt.Errorf("invalid path param name: %s", v.name)
+		}
+		if !v.explode && v.prefix == 0 && !v.catchAll {
+			// Dynamic parameter
+			seg := pathSegment{isParam: true, paramName: v.name}
+			if v.constraint != "" {
+				constraint, err := compileConstraint(v.constraint)
+				if err != nil {
+					return pathSegment{}, err
+				}
+				seg.constraint = regexp.MustCompile("^(?:" + constraint + ")$")
+			}
+			return seg, nil
+		}
+	}
+
+	// Mixed segment
+	var parts []segmentPart
+	addLiteral := func(s string) {
+		if n := len(parts); n > 0 && parts[n-1].paramName == "" {
+			parts[n-1].literal += s
+			return
+		}
+		parts = append(parts, segmentPart{literal: s})
+	}
+	for _, t := range tokens {
+		if t.expr == nil {
+			literal, err := url.PathUnescape(t.literal)
+			if err != nil {
+				return pathSegment{}, fmt.Errorf("%w: %w", ErrInvalidURI, err)
+			}
+			addLiteral(literal)
+			continue
+		}
+
+		if err := validateExpression(t.expr); err != nil {
+			return pathSegment{}, fmt.Errorf("invalid path param name: %w", err)
+		}
+		sep := ","
+		switch t.expr.op {
+		case 0:
+		case '.':
+			sep = "."
+		default:
+			return pathSegment{}, fmt.Errorf("operator %q is not supported in a path segment: %s", t.expr.op, t.raw)
+		}
+		for i, v := range t.expr.vars {
+			if v.explode {
+				return pathSegment{}, fmt.Errorf("explode modifier is not supported in a path segment: %s", t.raw)
+			}
+			if v.catchAll {
+				return pathSegment{}, fmt.Errorf("catch-all param must be a whole path segment: %s", t.raw)
+			}
+			if i > 0 || t.expr.op == '.' {
+				addLiteral(sep)
+			}
+			part := segmentPart{paramName: v.name, maxLen: v.prefix}
+			if v.constraint != "" {
+				constraint, err := compileConstraint(v.constraint)
+				if err != nil {
+					return pathSegment{}, err
+				}
+				part.constraint = constraint
+			}
+			parts = append(parts, part)
+		}
+	}
+
+	// The parameters are captured by named groups,
+	// as the constraints can contain groups themselves.
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	for i, p := range parts {
+		switch {
+		case p.paramName == "":
+			pattern.WriteString(regexp.QuoteMeta(p.literal))
+		case p.constraint != "":
+			fmt.Fprintf(&pattern, "(?P<p%d>%s)", i, p.constraint)
+		case p.maxLen > 0:
+			fmt.Fprintf(&pattern, "(?P<p%d>.{1,%d}?)", i, p.maxLen)
+		default:
+			fmt.Fprintf(&pattern, "(?P<p%d>.+?)", i)
+		}
+	}
+	pattern.WriteString("$")
+
+	return pathSegment{parts: parts, re: regexp.MustCompile(pattern.String())}, nil
+}
+
+// parseFragmentTemplate parses the fragment of a URI template.
+// It returns the name of the param capturing the fragment, or empty if the fragment is not captured.
+// A literal fragment is ignored in matching.
+func parseFragmentTemplate(tokens []templateToken) (string, error) {
+	var name string
+	for _, t := range tokens {
+		if t.expr == nil {
+			continue
+		}
+		if name != "" || len(t.expr.vars) != 1 || (t.expr.op != '#' && t.expr.op != 0 && t.expr.op != '+') {
+			return "", fmt.Errorf("fragment must be captured by a single variable: %s", rawTemplate(tokens))
+		}
+		if err := validateExpression(t.expr); err != nil {
+			return "", fmt.Errorf("invalid fragment param name: %w", err)
+		}
+		name = t.expr.vars[0].name
+	}
+	return name, nil
+}
+
 // checkConflict verifies that a new route doesn't conflict with existing routes.
-func (m *Mux[T]) checkConflict(newRoute route[T]) error {
-	for _, rt := range m.routes {
+// others are the routes to check against.
+func (m *Mux[T]) checkConflict(newRoute route[T], others []*route[T]) error {
+	for _, rt := range others {
 		// Check if identical static routes are not duplicated (same scheme, host fixed/param, path, query pattern)
 		// Or if dynamic routes cover the same pattern
-		if isSameCoverage(rt, newRoute) {
+		if isSameCoverage(*rt, newRoute) {
 			// Already have same (or same coverage) route
-			return fmt.Errorf("conflict route: %v", routeToString(newRoute))
+			return fmt.Errorf("conflict route: %v", newRoute.pattern)
 		}
 	}
 	return nil
@@ -319,8 +914,17 @@ func isSameCoverage[T any](a, b route[T]) bool {
 			return false
 		} else {
 			if sA.isParam && sB.isParam {
-				// Both dynamic means same coverage
+				// Both dynamic means same coverage if the constraints are the same
 				// → Even if parameter names differ, same coverage
+				if constraintString(sA.constraint) != constraintString(sB.constraint) {
+					return false
+				}
+			} else if len(sA.parts) > 0 || len(sB.parts) > 0 {
+				// Mixed segments, check if the patterns match
+				// → Even if parameter names differ, same coverage
+				if sA.re == nil || sB.re == nil || sA.re.String() != sB.re.String() {
+					return false
+				}
 			} else {
 				// Both static, check if strings match
 				if sA.literal != sB.literal {
@@ -329,10 +933,23 @@ func isSameCoverage[T any](a, b route[T]) bool {
 			}
 		}
 	}
+	if (a.tail == nil) != (b.tail == nil) {
+		return false
+	}
+	if a.tail != nil {
+		// A tail capturing the rest of the path matches any number of segments from min,
+		// otherwise it matches up to the number of its params
+		if a.tail.rest != b.tail.rest || a.tail.min != b.tail.min {
+			return false
+		}
+		if !a.tail.rest && len(a.tail.names) != len(b.tail.names) {
+			return false
+		}
+	}
 
 	// 4) query
 	//   - Same coverage if exact match of key-value pairs
-	//   - In this case, "dynamic query" is prohibited by spec so checking fixed values is sufficient
+	//   - Query captures by "{?x}" are optional, so they don't affect coverage
 	if len(a.query) != len(b.query) {
 		return false
 	}
@@ -341,62 +958,62 @@ func isSameCoverage[T any](a, b route[T]) bool {
 			return false
 		}
 	}
+	//   - Same coverage if the required captures have the same keys and constraints
+	if len(a.queryCaptures) != len(b.queryCaptures) {
+		return false
+	}
+	for i, c := range a.queryCaptures {
+		c2 := b.queryCaptures[i]
+		if c.key != c2.key || constraintString(c.constraint) != constraintString(c2.constraint) {
+			return false
+		}
+	}
 
 	return true
 }
 
-// routeToString converts a route to its string representation for debugging.
-func routeToString[T any](r route[T]) string {
+// parsedURI represents a parsed URI with its components separated.
+type parsedURI struct {
+	scheme   string
+	host     string
+	pathSegs []string
+	query    url.Values // All actual received queries (including additional keys)
+	fragment string
+	// escapedQuery and escapedFragment are the query and the fragment as received
+	escapedQuery    string
+	escapedFragment string
+}
+
+// rest returns the URI with the same scheme and host, and the path segments after the first n segments.
+func (p *parsedURI) rest(n int) string {
 	var sb strings.Builder
-	sb.WriteString(r.scheme)
+	sb.WriteString(p.scheme)
 	sb.WriteString("://")
-	if r.hostIsParam {
-		sb.WriteString("{" + r.hostParamName + "}")
-	} else {
-		sb.WriteString(r.host)
-	}
+	sb.WriteString(p.host)
 	sb.WriteString("/")
-	for i, seg := range r.pathSegments {
+	for i, seg := range p.pathSegs[n:] {
 		if i > 0 {
 			sb.WriteString("/")
 		}
-		if seg.isParam {
-			sb.WriteString("{" + seg.paramName + "}")
-		} else {
-			sb.WriteString(seg.literal)
-		}
+		sb.WriteString(url.PathEscape(seg))
 	}
-	if len(r.query) > 0 {
+	if p.escapedQuery != "" {
 		sb.WriteString("?")
-		i := 0
-		for k, v := range r.query {
-			if i > 0 {
-				sb.WriteString("&")
-			}
-			sb.WriteString(k)
-			sb.WriteString("=")
-			sb.WriteString(v)
-			i++
-		}
+		sb.WriteString(p.escapedQuery)
+	}
+	if p.escapedFragment != "" {
+		sb.WriteString("#")
+		sb.WriteString(p.escapedFragment)
 	}
 	return sb.String()
 }
 
-// parsedURI represents a parsed URI with its components separated.
-type parsedURI struct {
-	scheme   string
-	host     string
-	pathSegs []string
-	query    map[string]string
-	rawQuery map[string]string // All actual received queries (including additional keys)
-}
-
 // parseRequest parses a raw URI string into a Request object and internal parsedURI structure.
 func (m *Mux[T]) parseRequest(rawURI string) (*Request, *parsedURI, error) {
 	// Extract scheme and host manually first
 	parts := strings.SplitN(rawURI, "://", 2)
 	if len(parts) != 2 {
-		return nil, nil, fmt.Errorf("invalid URI: scheme is required")
+		return nil, nil, fmt.Errorf("%w: scheme is required", ErrInvalidURI)
 	}
 	scheme := strings.ToLower(parts[0])
 
@@ -412,9 +1029,6 @@ func (m *Mux[T]) parseRequest(rawURI string) (*Request, *parsedURI, error) {
 		pathAndQuery = remainder[slashIndex:]
 	}
 
-	if host == "" {
-		return nil, nil, fmt.Errorf("invalid URI: host is required")
-	}
 	host = strings.ToLower(host)
 
 	// Parse path and query
@@ -429,38 +1043,42 @@ func (m *Mux[T]) parseRequest(rawURI string) (*Request, *parsedURI, error) {
 	} else {
 		u, err = url.Parse(scheme + "://" + host + pathAndQuery)
 		if err != nil {
-			return nil, nil, fmt.Errorf("invalid URI: %w", err)
+			return nil, nil, fmt.Errorf("%w: %w", ErrInvalidURI, err)
 		}
 	}
 
 	// path → normalize (consecutive slashes/trailing slash etc.)
-	pathSegs, err := parsePathForRequest(u.Path)
+	pathSegs, err := parsePathForRequest(u.EscapedPath())
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidURI, err)
 	}
 
 	// Parse query → key/value
 	reqQuery, err := parseQueryForRequest(u.RawQuery)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidURI, err)
 	}
 
 	// Request structure
 	req := &Request{
-		Query:  make(map[string]string),
-		Params: make(map[string]string),
+		Query:       make(map[string]string),
+		QueryValues: reqQuery,
+		Params:      make(map[string]string),
 	}
 	// Put all received queries into Request.Query (match determination is separate)
 	for k, v := range reqQuery {
-		req.Query[k] = v
+		req.Query[k] = v[0]
 	}
 
 	p := &parsedURI{
 		scheme:   scheme,
 		host:     host,
 		pathSegs: pathSegs,
-		query:    reqQuery, // Same object
-		rawQuery: reqQuery,
+		query:    reqQuery,
+		fragment: u.Fragment,
+
+		escapedQuery:    u.RawQuery,
+		escapedFragment: u.EscapedFragment(),
 	}
 
 	return req, p, nil
@@ -509,78 +1127,99 @@ func (m *Mux[T]) matchRoute(rt route[T], parsed *parsedURI) (map[string]string,
 	}
 
 	// 3) path
-	if len(rt.pathSegments) != len(parsed.pathSegs) {
+	if rt.tail == nil && len(rt.pathSegments) != len(parsed.pathSegs) {
+		return nil, false
+	}
+	if len(rt.pathSegments) > len(parsed.pathSegs) {
 		return nil, false
 	}
 	for i, seg := range rt.pathSegments {
 		got := parsed.pathSegs[i]
-		if seg.isParam {
+		switch {
+		case seg.isParam:
+			if seg.constraint != nil && !seg.constraint.MatchString(got) {
+				return nil, false
+			}
 			// Set parameter
 			params[seg.paramName] = got
-		} else {
+		case seg.re != nil:
+			// Match against mixed segment, and set parameters
+			matches := seg.re.FindStringSubmatch(got)
+			if matches == nil {
+				return nil, false
+			}
+			for i, part := range seg.parts {
+				if part.paramName != "" {
+					params[part.paramName] = matches[seg.re.SubexpIndex(fmt.Sprintf("p%d", i))]
+				}
+			}
+		default:
 			// Match against static segment (case-sensitive)
 			if seg.literal != got {
 				return nil, false
 			}
 		}
 	}
+	if rt.tail != nil && !matchTail(rt.tail, parsed.pathSegs[len(rt.pathSegments):], params) {
+		return nil, false
+	}
 
 	// 4) query
-	//   - For registered keys, must match exactly
+	//   - For registered keys, one of the values must match exactly
+	//   - For required captures, the key must exist and the first value must satisfy the constraint
 	//   - Extra keys in request are allowed
 	for k, v := range rt.query {
-		got, ok := parsed.query[k]
+		if !slices.Contains(parsed.query[k], v) {
+			return nil, false
+		}
+	}
+	for _, c := range rt.queryCaptures {
+		got, ok := parsed.query[c.key]
 		if !ok {
 			return nil, false
 		}
-		if got != v {
+		if c.constraint != nil && !c.constraint.MatchString(got[0]) {
 			return nil, false
 		}
+		params[c.paramName] = got[0]
+	}
+	for _, name := range rt.queryParams {
+		if got, ok := parsed.query[name]; ok {
+			params[name] = got[0]
+		}
+	}
+
+	// 5) fragment
+	if rt.fragmentParam != "" && parsed.fragment != "" {
+		params[rt.fragmentParam] = parsed.fragment
 	}
 
 	return params, true
 }
 
-// parsePath normalizes and parses a path string into path segments.
-// It removes consecutive slashes, trailing slashes, and empty segments.
-func parsePath(p string) ([]pathSegment, error) {
-	// Remove leading/trailing slashes first for easier normalization
-	trimmed := strings.TrimRight(p, "/")
-	// Split and rebuild to combine double slashes into one
-	rawSegs := strings.Split(trimmed, "/")
-	var segs []string
-	for _, s := range rawSegs {
-		if s == "" {
-			// Ignore between consecutive slashes
-			continue
-		}
-		segs = append(segs, s)
+// matchTail matches the remaining path segments against the tail, and sets the parameters.
+func matchTail(tail *pathTail, segs []string, params map[string]string) bool {
+	if len(segs) < tail.min {
+		return false
 	}
-
-	// Convert to pathSegment
-	ps := make([]pathSegment, 0, len(segs))
-	for _, s := range segs {
-		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
-			// Dynamic parameter
-			paramName := strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
-			if !isValidParamName(paramName) {
-				return nil, fmt.Errorf("invalid path param name: %s", paramName)
-			}
-			ps = append(ps, pathSegment{
-				isParam:   true,
-				paramName: paramName,
-			})
-		} else {
-			ps = append(ps, pathSegment{
-				isParam: false,
-				literal: s,
-			})
+	if !tail.rest && len(segs) > len(tail.names) {
+		return false
+	}
+	for i, name := range tail.names {
+		if i >= len(segs) {
+			break
+		}
+		if tail.rest && i == len(tail.names)-1 {
+			params[name] = strings.Join(segs[i:], "/")
+			break
 		}
+		params[name] = segs[i]
 	}
-	return ps, nil
+	return true
 }
 
-// parsePathForRequest normalizes and parses a path string for incoming requests.
+// parsePathForRequest normalizes and parses an escaped path string for incoming requests.
+// The path is split into segments before unescaping, so that an escaped slash "%2F" is kept in the segment.
 func parsePathForRequest(p string) ([]string, error) {
 	trimmed := strings.TrimRight(p, "/")
 	rawSegs := strings.Split(trimmed, "/")
@@ -589,64 +1228,132 @@ func parsePathForRequest(p string) ([]string, error) {
 		if s == "" {
 			continue
 		}
-		segs = append(segs, s)
+		seg, err := url.PathUnescape(s)
+		if err != nil {
+			return nil, err
+		}
+		segs = append(segs, seg)
 	}
 	return segs, nil
 }
 
-// parseQueryForRegistration parses query parameters during route registration.
+// parseQueryTemplate parses the query of a URI template during route registration.
+// It returns the fixed key-value pairs, the required captures like "page={page}",
+// and the names of the optional captures like "{?x,y}".
 // It enforces:
 // - No duplicate keys
 // - No empty keys
-// - No dynamic parameters
-func parseQueryForRegistration(q string) (map[string]string, error) {
-	if q == "" {
-		return map[string]string{}, nil
+// - No dynamic parameters in keys
+// - A value is either a literal or a single param
+func parseQueryTemplate(tokens []templateToken) (map[string]string, []queryCapture, []string, error) {
+	// The expressions in the fixed part are replaced with the placeholders "{N}" to parse the query,
+	// as the constraints can contain characters like "&" and "+".
+	var raw strings.Builder
+	var exprs []templateToken
+	var optional []string
+	for _, t := range tokens {
+		switch {
+		case t.expr == nil:
+			raw.WriteString(t.literal)
+		case t.expr.op == '?' || t.expr.op == '&':
+			if err := validateExpression(t.expr); err != nil {
+				return nil, nil, nil, fmt.Errorf("invalid query param name: %w", err)
+			}
+			for _, v := range t.expr.vars {
+				optional = append(optional, v.name)
+			}
+		default:
+			fmt.Fprintf(&raw, "{%d}", len(exprs))
+			exprs = append(exprs, t)
+		}
 	}
-	values, err := url.ParseQuery(q)
+	restore := func(s string) string {
+		for i, t := range exprs {
+			s = strings.ReplaceAll(s, fmt.Sprintf("{%d}", i), t.raw)
+		}
+		return s
+	}
+
+	fixed := make(map[string]string)
+	if raw.Len() == 0 {
+		return fixed, nil, optional, nil
+	}
+	values, err := url.ParseQuery(raw.String())
 	if err != nil {
-		return nil, fmt.Errorf("invalid URI: %w", err)
+		return nil, nil, nil, fmt.Errorf("%w: %w", ErrInvalidURI, err)
 	}
-	result := make(map[string]string)
+
+	var captures []queryCapture
 	for k, arr := range values {
 		if k == "" {
-			return nil, fmt.Errorf("query key cannot be empty")
+			return nil, nil, nil, fmt.Errorf("query key cannot be empty")
 		}
-		if strings.HasPrefix(k, "{") && strings.HasSuffix(k, "}") {
-			return nil, fmt.Errorf("dynamic param in query is not allowed: %s", k)
+		if strings.Contains(k, "{") {
+			return nil, nil, nil, fmt.Errorf("dynamic param in query is not allowed: %s", restore(k))
 		}
 		if len(arr) > 1 {
 			// Multiple values for same key → Error
-			return nil, fmt.Errorf("duplicate query key: %s", k)
+			return nil, nil, nil, fmt.Errorf("duplicate query key: %s", k)
+		}
+
+		v := arr[0]
+		if !strings.Contains(v, "{") {
+			fixed[k] = v
+			continue
+		}
+		var n int
+		if _, err := fmt.Sscanf(v, "{%d}", &n); err != nil || fmt.Sprintf("{%d}", n) != v || exprs[n].expr.op != 0 || len(exprs[n].expr.vars) != 1 {
+			return nil, nil, nil, fmt.Errorf("query value must be a literal or a single param: %s=%s", k, restore(v))
+		}
+		capture, err := parseQueryCapture(k, exprs[n].expr.vars[0])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		captures = append(captures, capture)
+	}
+	// Sort the captures for deterministic matching and comparison
+	slices.SortFunc(captures, func(a, b queryCapture) int {
+		return strings.Compare(a.key, b.key)
+	})
+
+	return fixed, captures, optional, nil
+}
+
+// parseQueryCapture parses the param of a required query capture like "page={page}".
+func parseQueryCapture(key string, v varSpec) (queryCapture, error) {
+	if !isValidParamName(v.name) {
+		return queryCapture{}, fmt.Errorf("invalid query param name: %s", v.name)
+	}
+	if v.explode || v.prefix > 0 || v.catchAll {
+		return queryCapture{}, fmt.Errorf("modifier is not supported in query param: %s", v.name)
+	}
+	capture := queryCapture{key: key, paramName: v.name}
+	if v.constraint != "" {
+		constraint, err := compileConstraint(v.constraint)
+		if err != nil {
+			return queryCapture{}, err
 		}
-		result[k] = arr[0]
+		capture.constraint = regexp.MustCompile("^(?:" + constraint + ")$")
 	}
-	return result, nil
+	return capture, nil
 }
 
 // parseQueryForRequest parses query parameters from incoming requests.
+// A key can have multiple values, in the order of appearance.
 // It enforces:
-// - No duplicate keys
 // - No empty keys
-func parseQueryForRequest(q string) (map[string]string, error) {
+func parseQueryForRequest(q string) (url.Values, error) {
 	if q == "" {
-		return map[string]string{}, nil
+		return url.Values{}, nil
 	}
 	values, err := url.ParseQuery(q)
 	if err != nil {
 		return nil, err
 	}
-	result := make(map[string]string)
-	for k, arr := range values {
-		if k == "" {
-			return nil, fmt.Errorf("query key cannot be empty")
-		}
-		if len(arr) > 1 {
-			return nil, fmt.Errorf("duplicate query key: %s", k)
-		}
-		result[k] = arr[0]
+	if _, ok := values[""]; ok {
+		return nil, fmt.Errorf("query key cannot be empty")
 	}
-	return result, nil
+	return values, nil
 }
 
 // paramNamePattern defines the allowed characters in parameter names.
@@ -659,19 +1366,72 @@ func isValidParamName(name string) bool {
 }
 
 // checkParamNameDuplication verifies that parameter names are not duplicated
-// within a route's host and path segments.
-func checkParamNameDuplication(hostParamName string, pathSegs []pathSegment) error {
+// within a route's host, path segments, query and fragment.
+func checkParamNameDuplication[T any](r route[T]) error {
 	used := make(map[string]struct{})
-	if hostParamName != "" {
-		used[hostParamName] = struct{}{}
+	for _, name := range paramNames(r) {
+		if _, exists := used[name]; exists {
+			return fmt.Errorf("param name duplicated: %s", name)
+		}
+		used[name] = struct{}{}
+	}
+	return nil
+}
+
+// paramNames returns the names of the parameters of a route in the order of appearance.
+func paramNames[T any](r route[T]) []string {
+	var names []string
+	if r.hostIsParam {
+		names = append(names, r.hostParamName)
 	}
-	for _, seg := range pathSegs {
+	for _, seg := range r.pathSegments {
 		if seg.isParam {
-			if _, exists := used[seg.paramName]; exists {
-				return fmt.Errorf("param name duplicated: %s", seg.paramName)
+			names = append(names, seg.paramName)
+		}
+		for _, part := range seg.parts {
+			if part.paramName != "" {
+				names = append(names, part.paramName)
 			}
-			used[seg.paramName] = struct{}{}
 		}
 	}
-	return nil
+	if r.tail != nil {
+		names = append(names, r.tail.names...)
+	}
+	for _, c := range r.queryCaptures {
+		names = append(names, c.paramName)
+	}
+	names = append(names, r.queryParams...)
+	if r.fragmentParam != "" {
+		names = append(names, r.fragmentParam)
+	}
+	return names
+}
+
+// constraintTypes maps the names of the type constraints to their regular expressions.
+var constraintTypes = map[string]string{
+	"int":   `[-+]?[0-9]+`,
+	"uint":  `[0-9]+`,
+	"float": `[-+]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+)(?:[eE][-+]?[0-9]+)?`,
+	"bool":  `true|false`,
+	"uuid":  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+}
+
+// compileConstraint returns the regular expression of a constraint,
+// which is either the name of a type in constraintTypes or a regular expression.
+func compileConstraint(constraint string) (string, error) {
+	if re, ok := constraintTypes[constraint]; ok {
+		return re, nil
+	}
+	if _, err := regexp.Compile(constraint); err != nil {
+		return "", fmt.Errorf("invalid param constraint: %w", err)
+	}
+	return constraint, nil
+}
+
+// constraintString returns the source of a constraint, or empty if re is nil.
+func constraintString(re *regexp.Regexp) string {
+	if re == nil {
+		return ""
+	}
+	return re.String()
 }
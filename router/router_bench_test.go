@@ -0,0 +1,83 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func BenchmarkMux_Execute(b *testing.B) {
+	for _, n := range []int{10, 1000, 10000} {
+		m := NewMux[string]()
+		for i := range n {
+			// Mix static, param and constrained routes, as a server with many resources would have
+			uris := []string{
+				fmt.Sprintf("example://resource-%d/items", i),
+				fmt.Sprintf("example://resource-%d/items/{id}", i),
+				fmt.Sprintf("example://resource-%d/items/{id}/revisions/{rev:int}", i),
+			}
+			for _, uri := range uris {
+				if err := m.HandleFunc(uri, func(ctx context.Context, req *Request) (string, error) {
+					return "", nil
+				}); err != nil {
+					b.Fatalf("HandleFunc(%s) error = %v", uri, err)
+				}
+			}
+		}
+
+		requests := map[string]string{
+			"static": fmt.Sprintf("example://resource-%d/items", n-1),
+			"param":  fmt.Sprintf("example://resource-%d/items/abc", n-1),
+			"nested": fmt.Sprintf("example://resource-%d/items/abc/revisions/42", n-1),
+		}
+		if _, err := m.Execute(context.Background(), requests["static"]); err != nil {
+			b.Fatalf("Execute() error = %v", err)
+		}
+		for _, kind := range []string{"static", "param", "nested"} {
+			uri := requests[kind]
+			b.Run(fmt.Sprintf("routes=%d/%s", n, kind), func(b *testing.B) {
+				b.ReportAllocs()
+				for b.Loop() {
+					if _, err := m.Execute(context.Background(), uri); err != nil {
+						b.Fatalf("Execute(%s) error = %v", uri, err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkMux_RegisterExecute(b *testing.B) {
+	for _, n := range []int{10, 1000, 10000} {
+		m := NewMux[string]()
+		h := func(ctx context.Context, req *Request) (string, error) {
+			return "", nil
+		}
+		for i := range n {
+			uri := fmt.Sprintf("example://tenant-%d/items/{id}", i)
+			if err := m.HandleFunc(uri, h); err != nil {
+				b.Fatalf("HandleFunc(%s) error = %v", uri, err)
+			}
+		}
+
+		// A tenant comes and goes while the requests of the others are executed
+		b.Run(fmt.Sprintf("routes=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			const uri = "example://tenant-new/items/{id}"
+			for b.Loop() {
+				if err := m.HandleFunc(uri, h); err != nil {
+					b.Fatalf("HandleFunc(%s) error = %v", uri, err)
+				}
+				if _, err := m.Execute(context.Background(), "example://tenant-new/items/abc"); err != nil {
+					b.Fatalf("Execute() error = %v", err)
+				}
+				if _, err := m.Execute(context.Background(), fmt.Sprintf("example://tenant-%d/items/abc", n-1)); err != nil {
+					b.Fatalf("Execute() error = %v", err)
+				}
+				if !m.Unregister(uri) {
+					b.Fatalf("Unregister(%s) = false", uri)
+				}
+			}
+		})
+	}
+}
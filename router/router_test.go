@@ -2,8 +2,13 @@ package router
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestMux_Handle(t *testing.T) {
@@ -74,10 +79,14 @@ func TestMux_Handle(t *testing.T) {
 			errContains: "scheme is required",
 		},
 		{
-			name:        "invalid URI - missing host",
-			uri:         "http:///users",
-			wantErr:     true,
-			errContains: "host is required",
+			name:    "valid route with empty host",
+			uri:     "http:///users",
+			wantErr: false,
+		},
+		{
+			name:    "valid route with empty host and catch-all param",
+			uri:     "file:///{path...}",
+			wantErr: false,
 		},
 		{
 			name:        "invalid param name in path",
@@ -97,6 +106,95 @@ func TestMux_Handle(t *testing.T) {
 			wantErr:     true,
 			errContains: "invalid URI",
 		},
+		{
+			name:    "valid route with reserved expansion",
+			uri:     "file://localhost/{+path}",
+			wantErr: false,
+		},
+		{
+			name:    "valid route with path segment expansion",
+			uri:     "http://example.com/repos{/owner,repo}",
+			wantErr: false,
+		},
+		{
+			name:    "valid route with query and fragment expansion",
+			uri:     "http://example.com/search{?q,page}{#section}",
+			wantErr: false,
+		},
+		{
+			name:    "valid route with prefixed segment",
+			uri:     "http://example.com/files/file-{name}.txt",
+			wantErr: false,
+		},
+		{
+			name:    "valid route with catch-all param",
+			uri:     "file://localhost/{path...}",
+			wantErr: false,
+		},
+		{
+			name:    "valid route with constrained params",
+			uri:     "http://example.com/users/{id:int}/commits/{sha:[0-9a-f]{40}}",
+			wantErr: false,
+		},
+		{
+			name:    "valid route with query captures",
+			uri:     "http://example.com/users?page={page:int}&sort={sort}",
+			wantErr: false,
+		},
+		{
+			name:        "invalid route with query value mixing literal and param",
+			uri:         "http://example.com/users?q=prefix-{q}",
+			wantErr:     true,
+			errContains: "query value must be a literal or a single param",
+		},
+		{
+			name:        "invalid route with malformed query capture",
+			uri:         "http://example.com/users?page={page*}",
+			wantErr:     true,
+			errContains: "modifier is not supported in query param",
+		},
+		{
+			name:        "invalid route with catch-all param not at the end",
+			uri:         "http://example.com/{path...}/edit",
+			wantErr:     true,
+			errContains: "must be at the end of the path",
+		},
+		{
+			name:        "invalid route with catch-all param in a segment",
+			uri:         "http://example.com/files/file-{path...}",
+			wantErr:     true,
+			errContains: "must be a whole path segment",
+		},
+		{
+			name:        "invalid route with malformed constraint",
+			uri:         "http://example.com/users/{id:[0-9}",
+			wantErr:     true,
+			errContains: "invalid param constraint",
+		},
+		{
+			name:        "invalid route with path expression not at the end",
+			uri:         "http://example.com/{+path}/edit",
+			wantErr:     true,
+			errContains: "must be at the end of the path",
+		},
+		{
+			name:        "invalid route with reserved expansion in a segment",
+			uri:         "http://example.com/files/file-{+path}",
+			wantErr:     true,
+			errContains: "must be a whole path segment",
+		},
+		{
+			name:        "invalid route with unclosed expression",
+			uri:         "http://example.com/users/{id",
+			wantErr:     true,
+			errContains: "invalid URI template",
+		},
+		{
+			name:        "duplicate param names in query expansion",
+			uri:         "http://example.com/users/{id}{?id}",
+			wantErr:     true,
+			errContains: "param name duplicated",
+		},
 	}
 
 	for _, tt := range tests {
@@ -370,6 +468,195 @@ func TestMux_ParamExtraction(t *testing.T) {
 				"type": "web/page",
 			},
 		},
+		{
+			name:       "reserved expansion extraction",
+			routeURI:   "file://localhost/{+path}",
+			requestURI: "file://localhost/docs/guide/intro.md",
+			wantParams: map[string]string{
+				"path": "docs/guide/intro.md",
+			},
+			wantQuery: map[string]string{},
+		},
+		{
+			name:       "catch-all param with empty host",
+			routeURI:   "file:///{path...}",
+			requestURI: "file:///a/b",
+			wantParams: map[string]string{
+				"path": "a/b",
+			},
+			wantQuery: map[string]string{},
+		},
+		{
+			name:       "reserved expansion with empty host",
+			routeURI:   "file:///{+path}",
+			requestURI: "file:///docs/guide/intro.md",
+			wantParams: map[string]string{
+				"path": "docs/guide/intro.md",
+			},
+			wantQuery: map[string]string{},
+		},
+		{
+			name:        "empty host doesn't match a host",
+			routeURI:    "file:///{+path}",
+			requestURI:  "file://localhost/docs",
+			wantErr:     true,
+			errContains: "route not found",
+		},
+		{
+			name:        "reserved expansion requires a segment",
+			routeURI:    "file://localhost/docs/{+path}",
+			requestURI:  "file://localhost/docs",
+			wantErr:     true,
+			errContains: "route not found",
+		},
+		{
+			name:       "exploded path segments extraction",
+			routeURI:   "http://example.com/tree{/segments*}",
+			requestURI: "http://example.com/tree/a/b/c",
+			wantParams: map[string]string{
+				"segments": "a/b/c",
+			},
+			wantQuery: map[string]string{},
+		},
+		{
+			name:       "optional path segments extraction",
+			routeURI:   "http://example.com/repos{/owner,repo}",
+			requestURI: "http://example.com/repos/golang",
+			wantParams: map[string]string{
+				"owner": "golang",
+			},
+			wantQuery: map[string]string{},
+		},
+		{
+			name:        "too many path segments",
+			routeURI:    "http://example.com/repos{/owner,repo}",
+			requestURI:  "http://example.com/repos/golang/go/issues",
+			wantErr:     true,
+			errContains: "route not found",
+		},
+		{
+			name:       "query expansion extraction",
+			routeURI:   "http://example.com/search{?q,page}",
+			requestURI: "http://example.com/search?q=go&lang=en",
+			wantParams: map[string]string{
+				"q": "go",
+			},
+			wantQuery: map[string]string{
+				"q":    "go",
+				"lang": "en",
+			},
+		},
+		{
+			name:       "fragment expansion extraction",
+			routeURI:   "http://example.com/docs/{page}{#section}",
+			requestURI: "http://example.com/docs/intro#install",
+			wantParams: map[string]string{
+				"page":    "intro",
+				"section": "install",
+			},
+			wantQuery: map[string]string{},
+		},
+		{
+			name:       "prefixed segment extraction",
+			routeURI:   "http://example.com/files/file-{name}.txt",
+			requestURI: "http://example.com/files/file-report.txt",
+			wantParams: map[string]string{
+				"name": "report",
+			},
+			wantQuery: map[string]string{},
+		},
+		{
+			name:       "label expansion extraction",
+			routeURI:   "http://example.com/files/{name}{.ext}",
+			requestURI: "http://example.com/files/archive.tar.gz",
+			wantParams: map[string]string{
+				"name": "archive",
+				"ext":  "tar.gz",
+			},
+			wantQuery: map[string]string{},
+		},
+		{
+			name:       "catch-all param extraction",
+			routeURI:   "file://localhost/files/{path...}",
+			requestURI: "file://localhost/files/a/b/c.txt",
+			wantParams: map[string]string{
+				"path": "a/b/c.txt",
+			},
+			wantQuery: map[string]string{},
+		},
+		{
+			name:       "catch-all param matches empty rest",
+			routeURI:   "file://localhost/files/{path...}",
+			requestURI: "file://localhost/files/",
+			wantParams: map[string]string{},
+			wantQuery:  map[string]string{},
+		},
+		{
+			name:       "constrained param extraction",
+			routeURI:   "http://example.com/commits/{sha:[0-9a-f]{40}}",
+			requestURI: "http://example.com/commits/0123456789abcdef0123456789abcdef01234567",
+			wantParams: map[string]string{
+				"sha": "0123456789abcdef0123456789abcdef01234567",
+			},
+			wantQuery: map[string]string{},
+		},
+		{
+			name:        "constrained param mismatch",
+			routeURI:    "http://example.com/users/{id:int}",
+			requestURI:  "http://example.com/users/alice",
+			wantErr:     true,
+			errContains: "route not found",
+		},
+		{
+			name:       "constrained param in prefixed segment",
+			routeURI:   "http://example.com/reports/report-{year:[0-9]{4}}.csv",
+			requestURI: "http://example.com/reports/report-2024.csv",
+			wantParams: map[string]string{
+				"year": "2024",
+			},
+			wantQuery: map[string]string{},
+		},
+		{
+			name:       "required query capture extraction",
+			routeURI:   "http://example.com/users?page={page:int}",
+			requestURI: "http://example.com/users?page=2&page=3",
+			wantParams: map[string]string{
+				"page": "2",
+			},
+			wantQuery: map[string]string{
+				"page": "2",
+			},
+		},
+		{
+			name:        "required query capture missing",
+			routeURI:    "http://example.com/users?page={page}",
+			requestURI:  "http://example.com/users",
+			wantErr:     true,
+			errContains: "route not found",
+		},
+		{
+			name:        "required query capture not satisfying constraint",
+			routeURI:    "http://example.com/users?page={page:int}",
+			requestURI:  "http://example.com/users?page=first",
+			wantErr:     true,
+			errContains: "route not found",
+		},
+		{
+			name:       "fixed query matches one of multiple values",
+			routeURI:   "http://example.com/posts?tag=go",
+			requestURI: "http://example.com/posts?tag=rust&tag=go",
+			wantParams: map[string]string{},
+			wantQuery: map[string]string{
+				"tag": "rust",
+			},
+		},
+		{
+			name:        "prefixed segment mismatch",
+			routeURI:    "http://example.com/files/file-{name}.txt",
+			requestURI:  "http://example.com/files/report.txt",
+			wantErr:     true,
+			errContains: "route not found",
+		},
 	}
 
 	for _, tt := range tests {
@@ -522,6 +809,93 @@ func TestMux_RouteScoring(t *testing.T) {
 		}
 	})
 
+	t.Run("Prefixed segment prioritized over dynamic", func(t *testing.T) {
+		m := NewMux[string]()
+		m.HandleFunc("http://example.com/files/{name}", func(ctx context.Context, req *Request) (string, error) {
+			return "dynamic", nil
+		})
+		m.HandleFunc("http://example.com/files/{+path}", func(ctx context.Context, req *Request) (string, error) {
+			return "tail", nil
+		})
+		m.HandleFunc("http://example.com/files/file-{name}.txt", func(ctx context.Context, req *Request) (string, error) {
+			return "prefixed", nil
+		})
+
+		tests := map[string]string{
+			"http://example.com/files/file-a.txt": "prefixed",
+			"http://example.com/files/a.txt":      "dynamic",
+			"http://example.com/files/a/b.txt":    "tail",
+		}
+		for uri, want := range tests {
+			result, err := m.Execute(context.Background(), uri)
+			if err != nil {
+				t.Errorf("Execute(%s) error = %v", uri, err)
+			}
+			if result != want {
+				t.Errorf("Execute(%s) = %v, want %v", uri, result, want)
+			}
+		}
+	})
+
+	t.Run("Constrained param prioritized over unconstrained", func(t *testing.T) {
+		m := NewMux[string]()
+		m.HandleFunc("http://example.com/users/{name}", func(ctx context.Context, req *Request) (string, error) {
+			return "name", nil
+		})
+		m.HandleFunc("http://example.com/users/{id:int}", func(ctx context.Context, req *Request) (string, error) {
+			return "id", nil
+		})
+
+		tests := map[string]string{
+			"http://example.com/users/123":   "id",
+			"http://example.com/users/alice": "name",
+		}
+		for uri, want := range tests {
+			result, err := m.Execute(context.Background(), uri)
+			if err != nil {
+				t.Errorf("Execute(%s) error = %v", uri, err)
+			}
+			if result != want {
+				t.Errorf("Execute(%s) = %v, want %v", uri, result, want)
+			}
+		}
+	})
+
+	t.Run("Routes differing only by query", func(t *testing.T) {
+		m := NewMux[string]()
+		for _, uri := range []string{
+			"http://example.com/users",
+			"http://example.com/users?page={page}",
+			"http://example.com/users?page={page:int}",
+			"http://example.com/users?view=full",
+			"http://example.com/users?view=full&page={page:int}",
+		} {
+			if err := m.HandleFunc(uri, func(ctx context.Context, req *Request) (string, error) {
+				return uri, nil
+			}); err != nil {
+				t.Fatalf("HandleFunc(%s) error = %v", uri, err)
+			}
+		}
+
+		tests := map[string]string{
+			"http://example.com/users":                  "http://example.com/users",
+			"http://example.com/users?page=last":        "http://example.com/users?page={page}",
+			"http://example.com/users?page=2":           "http://example.com/users?page={page:int}",
+			"http://example.com/users?view=full":        "http://example.com/users?view=full",
+			"http://example.com/users?view=full&page=2": "http://example.com/users?view=full&page={page:int}",
+			"http://example.com/users?page=2&view=full": "http://example.com/users?view=full&page={page:int}",
+		}
+		for uri, want := range tests {
+			result, err := m.Execute(context.Background(), uri)
+			if err != nil {
+				t.Errorf("Execute(%s) error = %v", uri, err)
+			}
+			if result != want {
+				t.Errorf("Execute(%s) = %v, want %v", uri, result, want)
+			}
+		}
+	})
+
 	t.Run("Multiple dynamic segments", func(t *testing.T) {
 		m := NewMux[string]()
 		m.HandleFunc("http://{subdomain}.example.com/users/{id}/posts/{postId}", func(ctx context.Context, req *Request) (string, error) {
@@ -537,3 +911,323 @@ func TestMux_RouteScoring(t *testing.T) {
 		}
 	})
 }
+
+func TestMux_Patterns(t *testing.T) {
+	m := NewMux[string]()
+	patterns := []string{
+		"example://resource/{id}",
+		"http://{subdomain}.example.com/users/{id}",
+		"http://example.com/search?q=go",
+	}
+	for _, p := range patterns {
+		if err := m.HandleFunc(p, func(ctx context.Context, req *Request) (string, error) {
+			return "", nil
+		}); err != nil {
+			t.Fatalf("HandleFunc(%s) error = %v", p, err)
+		}
+	}
+
+	if got := m.Patterns(); !reflect.DeepEqual(got, patterns) {
+		t.Errorf("Patterns() = %v, want %v", got, patterns)
+	}
+}
+
+func TestMux_HandleConflict(t *testing.T) {
+	tests := []struct {
+		name         string
+		routes       []string
+		wantConflict bool
+	}{
+		{
+			name:         "same params with different names",
+			routes:       []string{"http://example.com/users/{id}", "http://example.com/users/{name}"},
+			wantConflict: true,
+		},
+		{
+			name:         "same constraints",
+			routes:       []string{"http://example.com/users/{id:int}", "http://example.com/users/{userID:int}"},
+			wantConflict: true,
+		},
+		{
+			name:         "different constraints",
+			routes:       []string{"http://example.com/users/{id:int}", "http://example.com/users/{id:uuid}"},
+			wantConflict: false,
+		},
+		{
+			name:         "constrained and unconstrained",
+			routes:       []string{"http://example.com/users/{id:int}", "http://example.com/users/{id}"},
+			wantConflict: false,
+		},
+		{
+			name:         "same query captures with different names",
+			routes:       []string{"http://example.com/users?page={page}", "http://example.com/users?page={p}"},
+			wantConflict: true,
+		},
+		{
+			name:         "different query captures",
+			routes:       []string{"http://example.com/users?page={page}", "http://example.com/users?offset={offset}"},
+			wantConflict: false,
+		},
+		{
+			name:         "catch-all and exploded segments",
+			routes:       []string{"http://example.com/files/{path...}", "http://example.com/files{/segments*}"},
+			wantConflict: true,
+		},
+		{
+			name:         "catch-all and reserved expansion",
+			routes:       []string{"http://example.com/files/{path...}", "http://example.com/files/{+path}"},
+			wantConflict: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMux[string]()
+			var err error
+			for _, uri := range tt.routes {
+				err = m.HandleFunc(uri, func(ctx context.Context, req *Request) (string, error) {
+					return "", nil
+				})
+			}
+			if (err != nil) != tt.wantConflict {
+				t.Errorf("HandleFunc() error = %v, wantConflict %v", err, tt.wantConflict)
+			}
+		})
+	}
+}
+
+// TestMux_HandleDocExamples checks the examples in the doc comment of Handle.
+func TestMux_HandleDocExamples(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		requestURI string
+		wantParams map[string]string
+	}{
+		{
+			pattern:    "http://example.com/users/{id}",
+			requestURI: "http://example.com/users/42",
+			wantParams: map[string]string{"id": "42"},
+		},
+		{
+			pattern:    "file:///{+path}",
+			requestURI: "file:///docs/guide/intro.md",
+			wantParams: map[string]string{"path": "docs/guide/intro.md"},
+		},
+		{
+			pattern:    "http://example.com/users?page={page:int}",
+			requestURI: "http://example.com/users?page=2",
+			wantParams: map[string]string{"page": "2"},
+		},
+		{
+			pattern:    "http://example.com/file-{name}.txt",
+			requestURI: "http://example.com/file-notes.txt",
+			wantParams: map[string]string{"name": "notes"},
+		},
+		{
+			pattern:    "http://example.com/{name}{.ext}",
+			requestURI: "http://example.com/notes.txt",
+			wantParams: map[string]string{"name": "notes", "ext": "txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			m := NewMux[map[string]string]()
+			if err := m.HandleFunc(tt.pattern, func(ctx context.Context, req *Request) (map[string]string, error) {
+				return req.Params, nil
+			}); err != nil {
+				t.Fatalf("HandleFunc() error = %v", err)
+			}
+
+			got, err := m.Execute(context.Background(), tt.requestURI)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !mapsEqual(got, tt.wantParams) {
+				t.Errorf("Params = %v, want %v", got, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestRequest_TypedParams(t *testing.T) {
+	req := &Request{Params: map[string]string{
+		"id":    "42",
+		"big":   "9007199254740993",
+		"ratio": "0.5",
+		"flag":  "true",
+		"name":  "alice",
+	}}
+
+	if got, err := req.ParamInt("id"); err != nil || got != 42 {
+		t.Errorf("ParamInt(id) = %v, %v; want 42", got, err)
+	}
+	if got, err := req.ParamInt64("big"); err != nil || got != 9007199254740993 {
+		t.Errorf("ParamInt64(big) = %v, %v; want 9007199254740993", got, err)
+	}
+	if got, err := req.ParamFloat64("ratio"); err != nil || got != 0.5 {
+		t.Errorf("ParamFloat64(ratio) = %v, %v; want 0.5", got, err)
+	}
+	if got, err := req.ParamBool("flag"); err != nil || !got {
+		t.Errorf("ParamBool(flag) = %v, %v; want true", got, err)
+	}
+	if _, err := req.ParamInt("name"); err == nil {
+		t.Error("ParamInt(name) error = nil, want error for non-integer")
+	}
+	if _, err := req.ParamInt("missing"); err == nil {
+		t.Error("ParamInt(missing) error = nil, want error for missing param")
+	}
+}
+
+func TestMux_Unregister(t *testing.T) {
+	m := NewMux[string]()
+	for _, uri := range []string{
+		"http://example.com/users/{id}",
+		"http://example.com/users/profile",
+	} {
+		if err := m.HandleFunc(uri, func(ctx context.Context, req *Request) (string, error) {
+			return uri, nil
+		}); err != nil {
+			t.Fatalf("HandleFunc(%s) error = %v", uri, err)
+		}
+	}
+
+	if got, err := m.Execute(context.Background(), "http://example.com/users/profile"); err != nil || got != "http://example.com/users/profile" {
+		t.Errorf("Execute() = %v, %v; want the static route", got, err)
+	}
+
+	if !m.Unregister("http://example.com/users/profile") {
+		t.Error("Unregister() = false, want true for a registered route")
+	}
+	if m.Unregister("http://example.com/users/profile") {
+		t.Error("Unregister() = true, want false for an unregistered route")
+	}
+	if got, err := m.Execute(context.Background(), "http://example.com/users/profile"); err != nil || got != "http://example.com/users/{id}" {
+		t.Errorf("Execute() = %v, %v; want the dynamic route after unregistering the static route", got, err)
+	}
+
+	if !m.Unregister("http://example.com/users/{id}") {
+		t.Error("Unregister() = false, want true for a registered route")
+	}
+	if _, err := m.Execute(context.Background(), "http://example.com/users/profile"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Execute() error = %v, want ErrNotFound", err)
+	}
+	if got := m.Patterns(); len(got) != 0 {
+		t.Errorf("Patterns() = %v, want empty", got)
+	}
+
+	// The same pattern can be registered again
+	if err := m.HandleFunc("http://example.com/users/{id}", func(ctx context.Context, req *Request) (string, error) {
+		return "again", nil
+	}); err != nil {
+		t.Errorf("HandleFunc() error = %v after Unregister", err)
+	}
+}
+
+func TestMux_Concurrent(t *testing.T) {
+	m := NewMux[string]()
+	if err := m.HandleFunc("example://tenants/{tenant}", func(ctx context.Context, req *Request) (string, error) {
+		return "fallback", nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			uri := fmt.Sprintf("example://tenants/tenant-%d", i)
+			for range 100 {
+				if err := m.HandleFunc(uri, func(ctx context.Context, req *Request) (string, error) {
+					return uri, nil
+				}); err != nil {
+					t.Errorf("HandleFunc(%s) error = %v", uri, err)
+					return
+				}
+				if !m.Unregister(uri) {
+					t.Errorf("Unregister(%s) = false", uri)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			uri := fmt.Sprintf("example://tenants/tenant-%d", i)
+			for range 100 {
+				got, err := m.Execute(context.Background(), uri)
+				if err != nil {
+					t.Errorf("Execute(%s) error = %v", uri, err)
+					return
+				}
+				if got != uri && got != "fallback" {
+					t.Errorf("Execute(%s) = %v", uri, got)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestMux_ExecuteWithoutLock(t *testing.T) {
+	m := NewMux[string]()
+	h := func(ctx context.Context, req *Request) (string, error) {
+		return req.Params["id"], nil
+	}
+	if err := m.HandleFunc("example://items/{id}", h); err != nil {
+		t.Fatal(err)
+	}
+	before := m.load()
+
+	// A change to the routes leaves the snapshots read by Execute unchanged
+	if !m.Unregister("example://items/{id}") {
+		t.Fatal("Unregister() = false")
+	}
+	if err := m.HandleFunc("example://items/{id}/children", h); err != nil {
+		t.Fatal(err)
+	}
+	if _, matched := m.findCandidates(before, &parsedURI{scheme: "example", host: "items", pathSegs: []string{"a"}}, nil); len(matched) != 1 {
+		t.Errorf("routes matched in the snapshot before the changes = %d, want 1", len(matched))
+	}
+
+	// Execute doesn't wait for a change in progress
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if got, err := m.Execute(context.Background(), "example://items/a/children"); err != nil || got != "a" {
+			t.Errorf("Execute() = %q, %v, want %q, nil", got, err, "a")
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Execute() blocked while the routes are being changed")
+	}
+}
+
+func TestRequest_QueryValues(t *testing.T) {
+	m := NewMux[string]()
+	var captured *Request
+	if err := m.HandleFunc("http://example.com/posts", func(ctx context.Context, req *Request) (string, error) {
+		captured = req
+		return "", nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Execute(context.Background(), "http://example.com/posts?tag=go&tag=rust&page=1"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got := captured.QueryValues["tag"]; !reflect.DeepEqual(got, []string{"go", "rust"}) {
+		t.Errorf("QueryValues[tag] = %v, want [go rust]", got)
+	}
+	if got := captured.QueryValues.Get("page"); got != "1" {
+		t.Errorf("QueryValues.Get(page) = %v, want 1", got)
+	}
+	if got := captured.Query["tag"]; got != "go" {
+		t.Errorf("Query[tag] = %v, want the first value go", got)
+	}
+}
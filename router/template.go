@@ -0,0 +1,367 @@
+package router
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// templateToken is a token of a URI template, which is either a literal or an expression.
+type templateToken struct {
+	literal string
+	// expr is the expression, or nil if the token is a literal.
+	expr *expression
+	// raw is the raw text of the expression, including the braces.
+	raw string
+}
+
+// expression is an RFC 6570 expression, e.g. "{?x,y}".
+type expression struct {
+	// op is the operator of the expression, or 0 for simple string expansion.
+	op   byte
+	vars []varSpec
+}
+
+// varSpec is a variable of an expression.
+type varSpec struct {
+	name    string
+	explode bool
+	// prefix is the maximum length of the value in characters, or 0 if not set.
+	prefix int
+	// catchAll is set for "{name...}", which captures the rest of the path in routing.
+	catchAll bool
+	// constraint is the type or the regular expression constraining the value in routing,
+	// e.g. "int" for "{id:int}" or "[0-9a-f]{40}" for "{sha:[0-9a-f]{40}}".
+	constraint string
+}
+
+// operators are the RFC 6570 operators supported in expressions.
+const operators = "+#./;?&"
+
+// tokenizeTemplate splits a URI template into literals and expressions.
+// The names of the variables are not validated.
+func tokenizeTemplate(s string) ([]templateToken, error) {
+	var tokens []templateToken
+	var lit strings.Builder
+	flush := func() {
+		if lit.Len() > 0 {
+			tokens = append(tokens, templateToken{literal: lit.String()})
+			lit.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '{':
+			end := closingBrace(s, i)
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed expression: %s", s[i:])
+			}
+			expr, err := parseExpression(s[i+1 : end])
+			if err != nil {
+				return nil, err
+			}
+			flush()
+			tokens = append(tokens, templateToken{expr: expr, raw: s[i : end+1]})
+			i = end + 1
+		case '}':
+			return nil, fmt.Errorf("unexpected '}' at %d", i)
+		default:
+			lit.WriteByte(s[i])
+			i++
+		}
+	}
+	flush()
+
+	return tokens, nil
+}
+
+// closingBrace returns the index of the brace closing the expression that starts at i.
+// closingBrace returns -1 if the expression is not closed.
+func closingBrace(s string, i int) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// parseExpression parses the body of an expression, that is, the text between the braces.
+func parseExpression(body string) (*expression, error) {
+	if body == "" {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	e := &expression{}
+	if strings.IndexByte(operators, body[0]) >= 0 {
+		e.op = body[0]
+		body = body[1:]
+	}
+
+	// A modifier other than a number is a constraint, e.g. "{id:int}".
+	// The constraint is the rest of the expression, as a regular expression can contain commas.
+	if name, constraint, ok := strings.Cut(body, ":"); ok && e.op == 0 {
+		modifier, _, _ := strings.Cut(constraint, ",")
+		if _, err := strconv.Atoi(modifier); err != nil {
+			if constraint == "" {
+				return nil, fmt.Errorf("empty constraint: %s", name)
+			}
+			e.vars = []varSpec{{name: name, constraint: constraint}}
+			return e, nil
+		}
+	}
+
+	for _, spec := range strings.Split(body, ",") {
+		var v varSpec
+		if name, ok := strings.CutSuffix(spec, "..."); ok {
+			if e.op != 0 {
+				return nil, fmt.Errorf("catch-all is not supported with operator %q: %s", e.op, spec)
+			}
+			v.catchAll = true
+			spec = name
+		} else if name, ok := strings.CutSuffix(spec, "*"); ok {
+			v.explode = true
+			spec = name
+		} else if name, modifier, ok := strings.Cut(spec, ":"); ok {
+			n, err := strconv.Atoi(modifier)
+			if err != nil || n <= 0 || n >= 10000 {
+				return nil, fmt.Errorf("invalid prefix modifier: %s", spec)
+			}
+			v.prefix = n
+			spec = name
+		}
+		v.name = spec
+		e.vars = append(e.vars, v)
+	}
+
+	return e, nil
+}
+
+// validateExpression checks that the names of the variables are valid param names.
+func validateExpression(e *expression) error {
+	for _, v := range e.vars {
+		if !isValidParamName(v.name) {
+			return fmt.Errorf("invalid param name: %s", v.name)
+		}
+	}
+	return nil
+}
+
+// Expand expands a URI template with the values as defined in RFC 6570.
+// A value is a string, a []string for a list, or a map[string]string for an associative array.
+// The routing extensions are accepted as well: "{name...}" is expanded like "{+name}",
+// and the constraint of "{name:constraint}" is ignored.
+// Variables without a value, or with an empty list or associative array, are undefined and expanded to nothing.
+// The keys of an associative array are expanded in lexical order.
+func Expand(template string, values map[string]any) (string, error) {
+	tokens, err := tokenizeTemplate(template)
+	if err != nil {
+		return "", fmt.Errorf("invalid URI template: %w", err)
+	}
+
+	var sb strings.Builder
+	for _, t := range tokens {
+		if t.expr == nil {
+			sb.WriteString(t.literal)
+			continue
+		}
+		if err := validateExpression(t.expr); err != nil {
+			return "", fmt.Errorf("invalid URI template: %w", err)
+		}
+		if err := expandExpression(&sb, t.expr, values); err != nil {
+			return "", err
+		}
+	}
+	return sb.String(), nil
+}
+
+// operatorSpec is the behavior of an operator in expansion.
+// See RFC 6570 Appendix A.
+type operatorSpec struct {
+	first         string
+	sep           string
+	named         bool
+	ifEmpty       string
+	allowReserved bool
+}
+
+// operatorSpecs maps an operator to its behavior in expansion.
+var operatorSpecs = map[byte]operatorSpec{
+	0:   {first: "", sep: ","},
+	'+': {first: "", sep: ",", allowReserved: true},
+	'.': {first: ".", sep: "."},
+	'/': {first: "/", sep: "/"},
+	';': {first: ";", sep: ";", named: true},
+	'?': {first: "?", sep: "&", named: true, ifEmpty: "="},
+	'&': {first: "&", sep: "&", named: true, ifEmpty: "="},
+	'#': {first: "#", sep: ",", allowReserved: true},
+}
+
+// expandExpression writes the expansion of the expression to sb.
+func expandExpression(sb *strings.Builder, e *expression, values map[string]any) error {
+	spec := operatorSpecs[e.op]
+
+	first := true
+	for _, v := range e.vars {
+		spec := spec
+		if v.catchAll {
+			spec.allowReserved = true
+		}
+		value, ok := values[v.name]
+		if !ok || value == nil {
+			continue
+		}
+
+		var expanded string
+		switch value := value.(type) {
+		case string:
+			expanded = expandString(spec, v, value)
+		case []string:
+			if len(value) == 0 {
+				continue
+			}
+			if v.prefix > 0 {
+				return fmt.Errorf("prefix modifier is not applicable to list: %s", v.name)
+			}
+			expanded = expandList(spec, v, value)
+		case map[string]string:
+			if len(value) == 0 {
+				continue
+			}
+			if v.prefix > 0 {
+				return fmt.Errorf("prefix modifier is not applicable to associative array: %s", v.name)
+			}
+			expanded = expandMap(spec, v, value)
+		default:
+			return fmt.Errorf("unsupported value type for %s: %T", v.name, value)
+		}
+
+		if first {
+			sb.WriteString(spec.first)
+			first = false
+		} else {
+			sb.WriteString(spec.sep)
+		}
+		sb.WriteString(expanded)
+	}
+	return nil
+}
+
+// expandString expands a string value.
+func expandString(spec operatorSpec, v varSpec, value string) string {
+	if v.prefix > 0 && utf8.RuneCountInString(value) > v.prefix {
+		value = string([]rune(value)[:v.prefix])
+	}
+	encoded := encodeValue(value, spec.allowReserved)
+	if !spec.named {
+		return encoded
+	}
+	if value == "" {
+		return v.name + spec.ifEmpty
+	}
+	return v.name + "=" + encoded
+}
+
+// expandList expands a list value.
+func expandList(spec operatorSpec, v varSpec, value []string) string {
+	items := make([]string, len(value))
+	for i, item := range value {
+		items[i] = encodeValue(item, spec.allowReserved)
+		if v.explode && spec.named {
+			if item == "" {
+				items[i] = v.name + spec.ifEmpty
+			} else {
+				items[i] = v.name + "=" + items[i]
+			}
+		}
+	}
+
+	if v.explode {
+		return strings.Join(items, spec.sep)
+	}
+	joined := strings.Join(items, ",")
+	if spec.named {
+		return v.name + "=" + joined
+	}
+	return joined
+}
+
+// expandMap expands an associative array value.
+func expandMap(spec operatorSpec, v varSpec, value map[string]string) string {
+	keys := make([]string, 0, len(value))
+	for k := range value {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	items := make([]string, 0, len(keys))
+	for _, k := range keys {
+		key := encodeValue(k, spec.allowReserved)
+		val := encodeValue(value[k], spec.allowReserved)
+		if v.explode {
+			if value[k] == "" {
+				items = append(items, key+spec.ifEmpty)
+			} else {
+				items = append(items, key+"="+val)
+			}
+		} else {
+			items = append(items, key, val)
+		}
+	}
+
+	if v.explode {
+		return strings.Join(items, spec.sep)
+	}
+	joined := strings.Join(items, ",")
+	if spec.named {
+		return v.name + "=" + joined
+	}
+	return joined
+}
+
+// encodeValue percent-encodes the characters of s that are not allowed in the expansion.
+// Unreserved characters are always allowed.
+// If allowReserved is true, reserved characters and percent-encoded triplets are allowed as well.
+func encodeValue(s string, allowReserved bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isUnreserved(c):
+			sb.WriteByte(c)
+		case allowReserved && isReserved(c):
+			sb.WriteByte(c)
+		case allowReserved && c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			sb.WriteString(s[i : i+3])
+			i += 2
+		default:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+// isUnreserved reports whether c is an unreserved character of RFC 3986.
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-._~", c) >= 0
+}
+
+// isReserved reports whether c is a reserved character of RFC 3986.
+func isReserved(c byte) bool {
+	return strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0
+}
+
+// isHex reports whether c is a hexadecimal digit.
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,103 @@
+package router
+
+import "testing"
+
+func TestExpand(t *testing.T) {
+	// Examples from RFC 6570 Section 3.2
+	values := map[string]any{
+		"count": []string{"one", "two", "three"},
+		"dom":   []string{"example", "com"},
+		"dub":   "me/too",
+		"hello": "Hello World!",
+		"half":  "50%",
+		"var":   "value",
+		"who":   "fred",
+		"base":  "http://example.com/home/",
+		"path":  "/foo/bar",
+		"list":  []string{"red", "green", "blue"},
+		"keys":  map[string]string{"semi": ";", "dot": ".", "comma": ","},
+		"v":     "6",
+		"x":     "1024",
+		"y":     "768",
+		"empty": "",
+	}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{template: "{var}", want: "value"},
+		{template: "{hello}", want: "Hello%20World%21"},
+		{template: "{half}", want: "50%25"},
+		{template: "O{empty}X", want: "OX"},
+		{template: "O{undef}X", want: "OX"},
+		{template: "{x,y}", want: "1024,768"},
+		{template: "{var:3}", want: "val"},
+		{template: "{list}", want: "red,green,blue"},
+		{template: "{list*}", want: "red,green,blue"},
+		{template: "{keys}", want: "comma,%2C,dot,.,semi,%3B"},
+		{template: "{keys*}", want: "comma=%2C,dot=.,semi=%3B"},
+		{template: "{+var}", want: "value"},
+		{template: "{+hello}", want: "Hello%20World!"},
+		{template: "{+path}/here", want: "/foo/bar/here"},
+		{template: "{+base}index", want: "http://example.com/home/index"},
+		{template: "{#var}", want: "#value"},
+		{template: "{#hello}", want: "#Hello%20World!"},
+		{template: "{#undef}", want: ""},
+		{template: "X{.var}", want: "X.value"},
+		{template: "X{.list*}", want: "X.red.green.blue"},
+		{template: "{/var}", want: "/value"},
+		{template: "{/var,x}/here", want: "/value/1024/here"},
+		{template: "{/list*}", want: "/red/green/blue"},
+		{template: "{/list*,path:4}", want: "/red/green/blue/%2Ffoo"},
+		{template: "{;x,y}", want: ";x=1024;y=768"},
+		{template: "{;x,y,empty}", want: ";x=1024;y=768;empty"},
+		{template: "{?x,y}", want: "?x=1024&y=768"},
+		{template: "{?x,y,empty}", want: "?x=1024&y=768&empty="},
+		{template: "{?list*}", want: "?list=red&list=green&list=blue"},
+		{template: "{?keys*}", want: "?comma=%2C&dot=.&semi=%3B"},
+		{template: "?fixed=yes{&x}", want: "?fixed=yes&x=1024"},
+		{template: "{&var:3}", want: "&var=val"},
+		{template: "www{.dom*}", want: "www.example.com"},
+		{template: "{count}", want: "one,two,three"},
+		{template: "{/count*}", want: "/one/two/three"},
+		{template: "{dub}", want: "me%2Ftoo"},
+		{template: "{v}", want: "6"},
+		{template: "{who}", want: "fred"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			got, err := Expand(tt.template, values)
+			if err != nil {
+				t.Fatalf("Expand() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpand_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		values   map[string]any
+	}{
+		{name: "unclosed expression", template: "{var", values: nil},
+		{name: "unexpected closing brace", template: "var}", values: nil},
+		{name: "invalid variable name", template: "{va@r}", values: nil},
+		{name: "invalid prefix", template: "{var:0}", values: nil},
+		{name: "prefix on list", template: "{list:3}", values: map[string]any{"list": []string{"a"}}},
+		{name: "unsupported value type", template: "{var}", values: map[string]any{"var": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Expand(tt.template, tt.values); err == nil {
+				t.Error("Expand() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package router
+
+import (
+	"cmp"
+	"maps"
+	"regexp"
+	"slices"
+)
+
+// routeTree indexes routes by scheme, host and path segments to find the candidate routes for a request
+// without scanning all the routes.
+//
+// routeTree is persistent: insert and remove return a new tree, copying only the nodes on the path of the route
+// and sharing the others with the original tree, which is left unchanged.
+// So a tree can be read by multiple goroutines without a lock while a new one is built.
+// The maps are cloned on write, which is cheap enough as routes are registered far less often than executed.
+type routeTree[T any] struct {
+	// hosts maps "scheme://host" to the root node for the routes with a fixed host
+	hosts map[string]*treeNode[T]
+	// paramHosts maps the scheme to the root node for the routes with a host param
+	paramHosts map[string]*treeNode[T]
+}
+
+// treeNode is a node of routeTree, corresponding to a path segment.
+// A node is never modified once it is reachable from a tree.
+type treeNode[T any] struct {
+	// static maps a literal segment to the child node
+	static map[string]*treeNode[T]
+	// param is the child node for a param segment without a constraint
+	param *treeNode[T]
+	// patterns are the child nodes for segments matched by a regular expression,
+	// that is, params with a constraint and segments mixing literals and params
+	patterns []patternNode[T]
+	// routes are the routes whose path ends at this node
+	routes []*route[T]
+	// tails are the routes whose tail starts at this node
+	tails []*route[T]
+}
+
+// patternNode is a child node for segments matched by a regular expression.
+type patternNode[T any] struct {
+	re   *regexp.Regexp
+	node *treeNode[T]
+}
+
+// roots returns the map of the root nodes containing the route, and the key of the route in it.
+func (t routeTree[T]) roots(r *route[T]) (map[string]*treeNode[T], string) {
+	if r.hostIsParam {
+		return t.paramHosts, r.scheme
+	}
+	return t.hosts, r.scheme + "://" + r.host
+}
+
+// withRoot returns a copy of the tree with the root node for the route replaced by n, or removed if n is empty.
+func (t routeTree[T]) withRoot(r *route[T], n *treeNode[T]) routeTree[T] {
+	roots, key := t.roots(r)
+	roots = maps.Clone(roots)
+	if n.isEmpty() {
+		delete(roots, key)
+	} else {
+		if roots == nil {
+			roots = make(map[string]*treeNode[T])
+		}
+		roots[key] = n
+	}
+	if r.hostIsParam {
+		t.paramHosts = roots
+	} else {
+		t.hosts = roots
+	}
+	return t
+}
+
+// node returns the node for a route, or nil if the node doesn't exist.
+func (t routeTree[T]) node(r *route[T]) *treeNode[T] {
+	roots, key := t.roots(r)
+	n, ok := roots[key]
+	if !ok {
+		return nil
+	}
+
+	for _, seg := range r.pathSegments {
+		if n = n.child(seg); n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// insert returns a copy of the tree with the route added.
+func (t routeTree[T]) insert(r *route[T]) routeTree[T] {
+	roots, key := t.roots(r)
+	root := roots[key]
+
+	// Copy the nodes from the root to the leaf, creating the missing ones
+	path := make([]*treeNode[T], 0, len(r.pathSegments)+1)
+	n := root
+	for i := 0; ; i++ {
+		path = append(path, n.copy())
+		if i == len(r.pathSegments) {
+			break
+		}
+		if n != nil {
+			n = n.child(r.pathSegments[i])
+		}
+	}
+
+	leaf := path[len(path)-1]
+	list := leaf.siblings(r)
+	*list = append(*list, r)
+	for i := len(path) - 1; i > 0; i-- {
+		path[i-1].setChild(r.pathSegments[i-1], path[i])
+	}
+	return t.withRoot(r, path[0])
+}
+
+// remove returns a copy of the tree with the route removed, pruning the nodes left empty.
+// remove returns t itself if the tree doesn't contain the route.
+func (t routeTree[T]) remove(r *route[T]) routeTree[T] {
+	roots, key := t.roots(r)
+	root, ok := roots[key]
+	if !ok {
+		return t
+	}
+
+	path := []*treeNode[T]{root}
+	for _, seg := range r.pathSegments {
+		n := path[len(path)-1].child(seg)
+		if n == nil {
+			return t
+		}
+		path = append(path, n)
+	}
+	if !slices.Contains(*path[len(path)-1].siblings(r), r) {
+		return t
+	}
+
+	// Copy the nodes from the leaf to the root
+	n := path[len(path)-1].copy()
+	list := n.siblings(r)
+	*list = slices.DeleteFunc(*list, func(rt *route[T]) bool { return rt == r })
+	for i := len(path) - 1; i > 0; i-- {
+		parent := path[i-1].copy()
+		if n.isEmpty() {
+			parent.removeChild(r.pathSegments[i-1])
+		} else {
+			parent.setChild(r.pathSegments[i-1], n)
+		}
+		n = parent
+	}
+	return t.withRoot(r, n)
+}
+
+// copy returns a shallow copy of the node, which can be modified without affecting n.
+// The children are shared with n, and so is the map of the static children, which is cloned on write by setChild and removeChild.
+// copy returns an empty node if n is nil.
+func (n *treeNode[T]) copy() *treeNode[T] {
+	if n == nil {
+		return &treeNode[T]{}
+	}
+	return &treeNode[T]{
+		static:   n.static,
+		param:    n.param,
+		patterns: slices.Clone(n.patterns),
+		routes:   slices.Clone(n.routes),
+		tails:    slices.Clone(n.tails),
+	}
+}
+
+// child returns the child node for a segment, or nil if the child doesn't exist.
+func (n *treeNode[T]) child(seg pathSegment) *treeNode[T] {
+	switch re := seg.pattern(); {
+	case seg.isParam && seg.constraint == nil:
+		return n.param
+	case re != nil:
+		for _, p := range n.patterns {
+			if p.re.String() == re.String() {
+				return p.node
+			}
+		}
+		return nil
+	default:
+		return n.static[seg.literal]
+	}
+}
+
+// setChild sets the child node for a segment.
+func (n *treeNode[T]) setChild(seg pathSegment, c *treeNode[T]) {
+	switch re := seg.pattern(); {
+	case seg.isParam && seg.constraint == nil:
+		n.param = c
+	case re != nil:
+		for i, p := range n.patterns {
+			if p.re.String() == re.String() {
+				n.patterns[i].node = c
+				return
+			}
+		}
+		n.patterns = append(n.patterns, patternNode[T]{re: re, node: c})
+	default:
+		static := maps.Clone(n.static)
+		if static == nil {
+			static = make(map[string]*treeNode[T])
+		}
+		static[seg.literal] = c
+		n.static = static
+	}
+}
+
+// removeChild removes the child node for a segment.
+func (n *treeNode[T]) removeChild(seg pathSegment) {
+	switch re := seg.pattern(); {
+	case seg.isParam && seg.constraint == nil:
+		n.param = nil
+	case re != nil:
+		n.patterns = slices.DeleteFunc(n.patterns, func(p patternNode[T]) bool { return p.re.String() == re.String() })
+	default:
+		n.static = maps.Clone(n.static)
+		delete(n.static, seg.literal)
+	}
+}
+
+// pattern returns the regular expression matching the segment,
+// or nil if the segment is a literal or a param without a constraint.
+func (seg pathSegment) pattern() *regexp.Regexp {
+	switch {
+	case seg.isParam:
+		return seg.constraint
+	default:
+		return seg.re
+	}
+}
+
+// siblings returns the routes stored in the same list of the node as r.
+// Routes with the same coverage are always in the same list.
+func (n *treeNode[T]) siblings(r *route[T]) *[]*route[T] {
+	if r.tail != nil {
+		return &n.tails
+	}
+	return &n.routes
+}
+
+// isEmpty reports whether the node has neither routes nor children.
+func (n *treeNode[T]) isEmpty() bool {
+	return len(n.routes) == 0 && len(n.tails) == 0 && len(n.static) == 0 && n.param == nil && len(n.patterns) == 0
+}
+
+// candidates appends the routes that may match the request to dst, in the order of registration.
+// The routes must be checked with matchRoute, as candidates doesn't check the host param and the query.
+func (t routeTree[T]) candidates(dst []*route[T], parsed *parsedURI) []*route[T] {
+	if n, ok := t.hosts[parsed.scheme+"://"+parsed.host]; ok {
+		dst = n.collect(dst, parsed.pathSegs)
+	}
+	if n, ok := t.paramHosts[parsed.scheme]; ok {
+		dst = n.collect(dst, parsed.pathSegs)
+	}
+	slices.SortFunc(dst, func(a, b *route[T]) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+	return dst
+}
+
+// collect appends the routes under the node that may match the path segments to dst.
+func (n *treeNode[T]) collect(dst []*route[T], segs []string) []*route[T] {
+	dst = append(dst, n.tails...)
+	if len(segs) == 0 {
+		return append(dst, n.routes...)
+	}
+
+	seg, rest := segs[0], segs[1:]
+	if c, ok := n.static[seg]; ok {
+		dst = c.collect(dst, rest)
+	}
+	if n.param != nil {
+		dst = n.param.collect(dst, rest)
+	}
+	for _, p := range n.patterns {
+		if p.re.MatchString(seg) {
+			dst = p.node.collect(dst, rest)
+		}
+	}
+	return dst
+}
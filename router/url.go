@@ -0,0 +1,133 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// HandleNamed registers a new route with a handler like Handle, and names the route.
+// The name is used to build URIs from the route with URL.
+func (m *Mux[T]) HandleNamed(name, uri string, h Handler[T]) error {
+	if name == "" {
+		return fmt.Errorf("route name cannot be empty")
+	}
+	return m.handle(name, uri, h)
+}
+
+// HandleFuncNamed registers a new route with a handler function like HandleFunc, and names the route.
+func (m *Mux[T]) HandleFuncNamed(name, uri string, f func(context.Context, *Request) (T, error)) error {
+	return m.HandleNamed(name, uri, HandlerFunc[T](f))
+}
+
+// URL builds a URI from the pattern of the named route and the params.
+// The params are escaped for the part of the URI they appear in;
+// for example, "/" is escaped in {param} but not in {+path}.
+// The params in the host and the path are required, except for the ones in {/a,b}, {/segments*} and {path...}.
+// The params with a constraint must satisfy it.
+// The params captured by "key={param}" in the query are required, and the ones in {?x,y} and {#frag} are optional.
+// URL returns an error if a required param is missing or a param is unknown.
+func (m *Mux[T]) URL(name string, params map[string]string) (string, error) {
+	m.mu.Lock()
+	rt, ok := m.names[name]
+	m.mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("unknown route name: %s", name)
+	}
+
+	known := make(map[string]struct{})
+	for _, n := range paramNames(*rt) {
+		known[n] = struct{}{}
+	}
+	for n := range params {
+		if _, ok := known[n]; !ok {
+			return "", fmt.Errorf("unknown param for route %s: %s", name, n)
+		}
+	}
+
+	required := func(n string) error {
+		if params[n] == "" {
+			return fmt.Errorf("missing required param for route %s: %s", name, n)
+		}
+		return nil
+	}
+	if rt.hostIsParam {
+		if err := required(rt.hostParamName); err != nil {
+			return "", err
+		}
+	}
+	for _, seg := range rt.pathSegments {
+		if seg.isParam {
+			if err := required(seg.paramName); err != nil {
+				return "", err
+			}
+			if seg.constraint != nil && !seg.constraint.MatchString(params[seg.paramName]) {
+				return "", fmt.Errorf("param for route %s does not satisfy the constraint %s: %s", name, seg.constraint, seg.paramName)
+			}
+		}
+		for _, part := range seg.parts {
+			if part.paramName == "" {
+				continue
+			}
+			if err := required(part.paramName); err != nil {
+				return "", err
+			}
+			if part.maxLen > 0 && utf8.RuneCountInString(params[part.paramName]) > part.maxLen {
+				return "", fmt.Errorf("param for route %s is longer than %d characters: %s", name, part.maxLen, part.paramName)
+			}
+			if part.constraint != "" && !regexp.MustCompile("^(?:"+part.constraint+")$").MatchString(params[part.paramName]) {
+				return "", fmt.Errorf("param for route %s does not satisfy the constraint %s: %s", name, part.constraint, part.paramName)
+			}
+		}
+	}
+
+	for _, c := range rt.queryCaptures {
+		if err := required(c.paramName); err != nil {
+			return "", err
+		}
+		if c.constraint != nil && !c.constraint.MatchString(params[c.paramName]) {
+			return "", fmt.Errorf("param for route %s does not satisfy the constraint %s: %s", name, c.constraint, c.paramName)
+		}
+	}
+
+	values := make(map[string]any, len(params))
+	for n, v := range params {
+		values[n] = v
+	}
+	if rt.tail != nil {
+		if rt.tail.min > 0 {
+			if err := required(rt.tail.names[0]); err != nil {
+				return "", err
+			}
+		}
+		// A segment cannot be omitted if a later segment is present
+		for i := 1; i < len(rt.tail.names); i++ {
+			if params[rt.tail.names[i]] != "" && params[rt.tail.names[i-1]] == "" {
+				return "", fmt.Errorf("missing param for route %s: %s is required by %s", name, rt.tail.names[i-1], rt.tail.names[i])
+			}
+		}
+		for _, n := range rt.tail.names {
+			if v, ok := values[n]; ok && v == "" {
+				delete(values, n)
+			}
+		}
+		// The rest of the path is expanded segment by segment, so that "/" separates segments
+		// and the other reserved characters like "?" and "#" are escaped.
+		if last := rt.tail.names[len(rt.tail.names)-1]; rt.tail.rest && params[last] != "" {
+			segs := strings.Split(strings.Trim(params[last], "/"), "/")
+			if rt.tail.op == '/' {
+				values[last] = segs
+			} else {
+				for i, seg := range segs {
+					segs[i] = url.PathEscape(seg)
+				}
+				values[last] = strings.Join(segs, "/")
+			}
+		}
+	}
+
+	return Expand(rt.pattern, values)
+}
@@ -0,0 +1,166 @@
+package router
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMux_URL(t *testing.T) {
+	m := NewMux[string]()
+	var captured map[string]string
+	routes := map[string]string{
+		"user":   "http://{tenant}.example.com/users/{id}",
+		"file":   "file://localhost/{+path}",
+		"tree":   "http://example.com/tree{/segments*}",
+		"repo":   "http://example.com/repos{/owner,repo}",
+		"search": "http://example.com/search{?q,page}",
+		"doc":    "http://example.com/docs/{page}{#section}",
+		"report": "http://example.com/reports/report-{year:4}.csv",
+		"static": "http://example.com/static/{path...}",
+		"commit": "http://example.com/commits/{sha:[0-9a-f]{7}}",
+		"page":   "http://example.com/pages?page={page:int}",
+	}
+	for name, uri := range routes {
+		if err := m.HandleFuncNamed(name, uri, func(ctx context.Context, req *Request) (string, error) {
+			captured = req.Params
+			return name, nil
+		}); err != nil {
+			t.Fatalf("HandleFuncNamed(%s) error = %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		route  string
+		params map[string]string
+		want   string
+	}{
+		{
+			name:   "host and path params",
+			route:  "user",
+			params: map[string]string{"tenant": "acme", "id": "a/b c"},
+			want:   "http://acme.example.com/users/a%2Fb%20c",
+		},
+		{
+			name:   "reserved expansion keeps slashes",
+			route:  "file",
+			params: map[string]string{"path": "docs/a b?.md"},
+			want:   "file://localhost/docs/a%20b%3F.md",
+		},
+		{
+			name:   "exploded segments",
+			route:  "tree",
+			params: map[string]string{"segments": "a/b c/d"},
+			want:   "http://example.com/tree/a/b%20c/d",
+		},
+		{
+			name:   "optional segments omitted",
+			route:  "repo",
+			params: map[string]string{"owner": "golang"},
+			want:   "http://example.com/repos/golang",
+		},
+		{
+			name:   "query params",
+			route:  "search",
+			params: map[string]string{"q": "a&b=c"},
+			want:   "http://example.com/search?q=a%26b%3Dc",
+		},
+		{
+			name:   "fragment",
+			route:  "doc",
+			params: map[string]string{"page": "intro", "section": "install"},
+			want:   "http://example.com/docs/intro#install",
+		},
+		{
+			name:   "prefixed segment",
+			route:  "report",
+			params: map[string]string{"year": "2024"},
+			want:   "http://example.com/reports/report-2024.csv",
+		},
+		{
+			name:   "catch-all",
+			route:  "static",
+			params: map[string]string{"path": "css/a b.css"},
+			want:   "http://example.com/static/css/a%20b.css",
+		},
+		{
+			name:   "required query capture",
+			route:  "page",
+			params: map[string]string{"page": "3"},
+			want:   "http://example.com/pages?page=3",
+		},
+		{
+			name:   "constrained param",
+			route:  "commit",
+			params: map[string]string{"sha": "0123abc"},
+			want:   "http://example.com/commits/0123abc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.URL(tt.route, tt.params)
+			if err != nil {
+				t.Fatalf("URL() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("URL() = %q, want %q", got, tt.want)
+			}
+
+			// The built URI is routed to the same route with the same params
+			result, err := m.Execute(context.Background(), got)
+			if err != nil {
+				t.Fatalf("Execute(%s) error = %v", got, err)
+			}
+			if result != tt.route {
+				t.Errorf("Execute(%s) = %v, want %v", got, result, tt.route)
+			}
+			if !mapsEqual(captured, tt.params) {
+				t.Errorf("Params = %v, want %v", captured, tt.params)
+			}
+		})
+	}
+}
+
+func TestMux_URLErrors(t *testing.T) {
+	m := NewMux[string]()
+	handler := func(ctx context.Context, req *Request) (string, error) { return "", nil }
+	if err := m.HandleFuncNamed("user", "http://example.com/users/{id:int}{?tab}", handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.HandleFuncNamed("repo", "http://example.com/repos{/owner,repo}", handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.HandleFuncNamed("report", "http://example.com/reports/report-{year:4}.csv", handler); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		route  string
+		params map[string]string
+	}{
+		{name: "unknown route", route: "unknown", params: nil},
+		{name: "missing required param", route: "user", params: map[string]string{"tab": "posts"}},
+		{name: "empty required param", route: "user", params: map[string]string{"id": ""}},
+		{name: "unknown param", route: "user", params: map[string]string{"id": "1", "other": "x"}},
+		{name: "omitted segment before present one", route: "repo", params: map[string]string{"repo": "go"}},
+		{name: "param not satisfying constraint", route: "user", params: map[string]string{"id": "alice"}},
+		{name: "param longer than prefix", route: "report", params: map[string]string{"year": "20245"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := m.URL(tt.route, tt.params); err == nil {
+				t.Errorf("URL() = %q, want error", got)
+			}
+		})
+	}
+
+	if err := m.HandleFuncNamed("user", "http://example.com/members/{id}", handler); err == nil {
+		t.Error("expected error for duplicated route name")
+	}
+	if err := m.HandleFuncNamed("", "http://example.com/members/{id}", handler); err == nil {
+		t.Error("expected error for empty route name")
+	}
+}
@@ -44,12 +44,13 @@ func (t *Generic) Receive() iter.Seq[json.RawMessage] {
 }
 
 // Send writes a JSON message to the writer, followed by a newline.
+// The message and the newline are written at once, so that a synchronous writer
+// such as io.Pipe does not block on the newline after the peer has read the message.
 func (t *Generic) Send(v json.RawMessage) error {
-	_, err := t.writer.Write(v)
-	if err != nil {
-		return err
-	}
-	_, err = t.writer.Write([]byte("\n"))
+	b := make([]byte, 0, len(v)+1)
+	b = append(b, v...)
+	b = append(b, '\n')
+	_, err := t.writer.Write(b)
 	return err
 }
 